@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"resourcepack-server/pack"
+	"resourcepack-server/server"
+)
+
+var commands = map[string]func(args []string) error{
+	"build":    runBuildCommand,
+	"validate": runValidateCommand,
+	"hash-key": runHashKeyCommand,
+}
+
+func runCommand(name string, args []string) int {
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "未知命令: %s\n", name)
+		return 2
+	}
+
+	if err := command(args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// runBuildCommand 离线打包目录资源包，产物与服务器实际提供下载的 ZIP 完全一致
+func runBuildCommand(args []string) error {
+	flags := flag.NewFlagSet("build", flag.ExitOnError)
+	output := flags.String("o", "", "输出 ZIP 文件路径，默认为 <目录名>.zip")
+	level := flags.Int("level", pack.DefaultCompressionLevel, "Deflate 压缩级别，需与配置项 zip_compression_level 一致")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("用法: resourcepack-server build [-o 输出文件] [-level 压缩级别] <资源包目录>")
+	}
+
+	dirPath := flags.Arg(0)
+	if _, err := os.Stat(filepath.Join(dirPath, "pack.mcmeta")); err != nil {
+		return fmt.Errorf("目录中缺少 pack.mcmeta: %s", dirPath)
+	}
+
+	outPath := *output
+	if outPath == "" {
+		absPath, err := filepath.Abs(dirPath)
+		if err != nil {
+			return err
+		}
+		outPath = filepath.Base(absPath) + ".zip"
+	}
+
+	builder, err := pack.NewZipBuilder(*level)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("创建输出文件失败: %w", err)
+	}
+
+	result, err := builder.Build(file, dirPath)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(outPath)
+		return fmt.Errorf("打包失败: %w", err)
+	}
+
+	fmt.Printf("输出文件: %s\n", outPath)
+	fmt.Printf("大小: %d\n", result.Size)
+	fmt.Printf("SHA-1: %s\n", result.Digests.SHA1)
+	fmt.Printf("SHA-256: %s\n", result.Digests.SHA256)
+	fmt.Printf("MD5: %s\n", result.Digests.MD5)
+	return nil
+}
+
+// runValidateCommand 校验 ZIP 或目录形式的资源包，存在错误时返回非零退出码
+func runValidateCommand(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "以 JSON 格式输出校验报告")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("用法: resourcepack-server validate [-json] <资源包 ZIP 或目录>")
+	}
+
+	report, err := pack.ValidatePackPath(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("校验失败: %w", err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		for _, issue := range report.Issues {
+			fmt.Printf("[%s] %s: %s (%s)\n", issue.Severity, issue.Path, issue.Message, issue.Code)
+		}
+		fmt.Printf("共检查 %d 个文件，%d 个错误，%d 个警告\n", report.CheckedFiles, report.Errors, report.Warnings)
+	}
+
+	if !report.Valid {
+		return fmt.Errorf("资源包 %s 未通过校验", report.Pack)
+	}
+	return nil
+}
+
+// runHashKeyCommand 输出 API Key 的 SHA-256，用于填写 [[auth.keys]] 的 key_sha256
+func runHashKeyCommand(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("用法: resourcepack-server hash-key <API Key>")
+	}
+
+	fmt.Println(server.HashAPIKey(args[0]))
+	return nil
+}
@@ -12,9 +12,13 @@ import (
 var configTemplate []byte
 
 type Config struct {
-	Server ServerConfig `mapstructure:"server"`
-	Packs  PacksConfig  `mapstructure:"packs"`
-	Log    LogConfig    `mapstructure:"logging"`
+	Server   ServerConfig    `mapstructure:"server"`
+	Packs    PacksConfig     `mapstructure:"packs"`
+	Log      LogConfig       `mapstructure:"logging"`
+	Auth     AuthConfig      `mapstructure:"auth"`
+	Signing  SigningConfig   `mapstructure:"signing"`
+	Webhooks []WebhookConfig `mapstructure:"webhooks"`
+	Stats    StatsConfig     `mapstructure:"stats"`
 }
 
 type ServerConfig struct {
@@ -24,10 +28,57 @@ type ServerConfig struct {
 }
 
 type PacksConfig struct {
-	Directory           string  `mapstructure:"directory"`
-	FileMonitor         bool    `mapstructure:"file_monitor"`
-	FileMonitorInterval float64 `mapstructure:"file_monitor_interval"`
-	ScanCooldown        float64 `mapstructure:"scan_cooldown"`
+	Directory             string  `mapstructure:"directory"`
+	FileMonitor           bool    `mapstructure:"file_monitor"`
+	FileMonitorMode       string  `mapstructure:"file_monitor_mode"`
+	FileMonitorInterval   float64 `mapstructure:"file_monitor_interval"`
+	ScanCooldown          float64 `mapstructure:"scan_cooldown"`
+	ScanWorkers           int     `mapstructure:"scan_workers"`
+	ZipCompressionLevel   int     `mapstructure:"zip_compression_level"`
+	SupersededGracePeriod float64 `mapstructure:"superseded_grace_period"`
+	FormatTable           string  `mapstructure:"format_table"`
+	MaxUploadSize         float64 `mapstructure:"max_upload_size"`
+	StateDir              string  `mapstructure:"state_dir"`
+	HistorySize           int     `mapstructure:"history_size"`
+}
+
+type AuthConfig struct {
+	Enabled     bool           `mapstructure:"enabled"`
+	PublicRoles []string       `mapstructure:"public_roles"`
+	Keys        []APIKeyConfig `mapstructure:"keys"`
+}
+
+type APIKeyConfig struct {
+	Name      string   `mapstructure:"name"`
+	KeySHA256 string   `mapstructure:"key_sha256"`
+	Roles     []string `mapstructure:"roles"`
+}
+
+type SigningConfig struct {
+	Required   bool               `mapstructure:"required"`
+	ClockSkew  float64            `mapstructure:"clock_skew"`
+	DefaultTTL float64            `mapstructure:"default_ttl"`
+	MaxTTL     float64            `mapstructure:"max_ttl"`
+	Keys       []SigningKeyConfig `mapstructure:"keys"`
+}
+
+type SigningKeyConfig struct {
+	ID     string `mapstructure:"id"`
+	Secret string `mapstructure:"secret"`
+}
+
+type WebhookConfig struct {
+	Name        string   `mapstructure:"name"`
+	URL         string   `mapstructure:"url"`
+	Secret      string   `mapstructure:"secret"`
+	Events      []string `mapstructure:"events"`
+	MaxAttempts int      `mapstructure:"max_attempts"`
+	Timeout     float64  `mapstructure:"timeout"`
+}
+
+type StatsConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	RetentionDays int  `mapstructure:"retention_days"`
 }
 
 type LogConfig struct {
@@ -46,8 +97,24 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("server.debug", false)
 	viper.SetDefault("packs.directory", "/resourcepacks")
 	viper.SetDefault("packs.file_monitor", true)
+	viper.SetDefault("packs.file_monitor_mode", "fsnotify")
 	viper.SetDefault("packs.file_monitor_interval", 1.0)
 	viper.SetDefault("packs.scan_cooldown", 2.0)
+	viper.SetDefault("packs.scan_workers", 0)
+	viper.SetDefault("packs.zip_compression_level", -1)
+	viper.SetDefault("packs.superseded_grace_period", 600.0)
+	viper.SetDefault("packs.format_table", "")
+	viper.SetDefault("packs.max_upload_size", 512.0)
+	viper.SetDefault("packs.state_dir", "data")
+	viper.SetDefault("packs.history_size", 5)
+	viper.SetDefault("auth.enabled", false)
+	viper.SetDefault("auth.public_roles", []string{"read", "download"})
+	viper.SetDefault("signing.required", false)
+	viper.SetDefault("signing.clock_skew", 30.0)
+	viper.SetDefault("signing.default_ttl", 3600.0)
+	viper.SetDefault("signing.max_ttl", 86400.0)
+	viper.SetDefault("stats.enabled", true)
+	viper.SetDefault("stats.retention_days", 90)
 	viper.SetDefault("logging.level", "INFO")
 	viper.SetDefault("logging.file", "logs/server.log")
 
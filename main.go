@@ -2,22 +2,33 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"resourcepack-server/config"
 	"resourcepack-server/pack"
 	"resourcepack-server/server"
+	"resourcepack-server/stats"
+	"resourcepack-server/webhook"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
 func main() {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	rebuildIndex := flag.Bool("rebuild-index", false, "忽略持久化的资源包索引，重新读取所有资源包")
+	flag.Parse()
 
 	logger := initLogger()
 	defer logger.Sync()
@@ -31,10 +42,19 @@ func main() {
 	logger.Info("配置加载成功")
 
 	packsConfig := &pack.Config{
-		Directory:           cfg.Packs.Directory,
-		FileMonitor:         cfg.Packs.FileMonitor,
-		FileMonitorInterval: time.Duration(cfg.Packs.FileMonitorInterval * float64(time.Second)),
-		ScanCooldown:        time.Duration(cfg.Packs.ScanCooldown * float64(time.Second)),
+		Directory:             cfg.Packs.Directory,
+		FileMonitor:           cfg.Packs.FileMonitor,
+		FileMonitorMode:       cfg.Packs.FileMonitorMode,
+		FileMonitorInterval:   time.Duration(cfg.Packs.FileMonitorInterval * float64(time.Second)),
+		ScanCooldown:          time.Duration(cfg.Packs.ScanCooldown * float64(time.Second)),
+		ScanWorkers:           cfg.Packs.ScanWorkers,
+		CompressionLevel:      cfg.Packs.ZipCompressionLevel,
+		SupersededGracePeriod: time.Duration(cfg.Packs.SupersededGracePeriod * float64(time.Second)),
+		FormatTableFile:       cfg.Packs.FormatTable,
+		MaxUploadSize:         int64(cfg.Packs.MaxUploadSize * 1024 * 1024),
+		StateDir:              cfg.Packs.StateDir,
+		HistorySize:           cfg.Packs.HistorySize,
+		RebuildIndex:          *rebuildIndex,
 	}
 
 	packsManager, err := pack.NewPacksManager(packsConfig, logger)
@@ -43,7 +63,22 @@ func main() {
 	}
 	logger.Info("资源包管理器初始化完成")
 
-	httpServer := server.NewServer(cfg, packsManager, logger)
+	dispatcher, err := webhook.NewDispatcher(cfg.Webhooks, cfg.Packs.StateDir, packsManager, logger)
+	if err != nil {
+		logger.Fatal("初始化 Webhook 失败", zap.Error(err))
+	}
+	dispatcher.Start()
+
+	var statsStore *stats.Store
+	if cfg.Stats.Enabled {
+		statsStore, err = stats.NewStore(filepath.Join(cfg.Packs.StateDir, "stats"), cfg.Stats.RetentionDays, logger)
+		if err != nil {
+			logger.Fatal("初始化下载统计失败", zap.Error(err))
+		}
+		defer statsStore.Close()
+	}
+
+	httpServer := server.NewServer(cfg, packsManager, dispatcher, statsStore, logger)
 	logger.Info("HTTP服务器初始化完成")
 
 	go func() {
@@ -55,7 +90,7 @@ func main() {
 		}
 	}()
 
-	waitForShutdown(logger, packsManager)
+	waitForShutdown(logger, packsManager, dispatcher)
 }
 
 func initLogger() *zap.Logger {
@@ -76,7 +111,7 @@ func initLogger() *zap.Logger {
 	return logger
 }
 
-func waitForShutdown(logger *zap.Logger, packsManager *pack.PacksManager) {
+func waitForShutdown(logger *zap.Logger, packsManager *pack.PacksManager, dispatcher *webhook.Dispatcher) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
@@ -88,6 +123,8 @@ func waitForShutdown(logger *zap.Logger, packsManager *pack.PacksManager) {
 	packsManager.StopFileMonitoring()
 	logger.Info("文件监控已停止")
 
+	dispatcher.Stop()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func render(c collector) string {
+	var builder strings.Builder
+	c.write(&builder)
+	return builder.String()
+}
+
+func TestCounterExposition(t *testing.T) {
+	c := NewCounter("test_downloads_total", "下载次数\n第二行 C:\\packs", "pack")
+	c.Inc("b")
+	c.Add(2.5, "a")
+	c.Inc(`quo"te\back` + "\nslash")
+
+	want := `# HELP test_downloads_total 下载次数\n第二行 C:\\packs
+# TYPE test_downloads_total counter
+test_downloads_total{pack="a"} 2.5
+test_downloads_total{pack="b"} 1
+test_downloads_total{pack="quo\"te\\back\nslash"} 1
+`
+	if got := render(c); got != want {
+		t.Fatalf("输出不一致:\n%s\n期望:\n%s", got, want)
+	}
+}
+
+func TestGaugeExposition(t *testing.T) {
+	g := NewGauge("test_temperature", "温度", "room", "floor")
+	g.Set(1e6, "lab", "2")
+	g.Set(math.Inf(-1), "hall", "1")
+	f := NewGaugeFunc("test_packs", "资源包数量", func() float64 { return 3 })
+
+	want := `# HELP test_temperature 温度
+# TYPE test_temperature gauge
+test_temperature{room="hall",floor="1"} -Inf
+test_temperature{room="lab",floor="2"} 1e+06
+`
+	if got := render(g); got != want {
+		t.Fatalf("输出不一致:\n%s\n期望:\n%s", got, want)
+	}
+	want = `# HELP test_packs 资源包数量
+# TYPE test_packs gauge
+test_packs 3
+`
+	if got := render(f); got != want {
+		t.Fatalf("输出不一致:\n%s\n期望:\n%s", got, want)
+	}
+}
+
+func TestHistogramExposition(t *testing.T) {
+	h := NewHistogram("test_duration_seconds", "耗时", []float64{0.005, 0.5, 1, 2.5}, "route")
+	for _, v := range []float64{0.001, 0.005, 0.3, 1, 3} {
+		h.Observe(v, "/download/:name")
+	}
+	h.Observe(0.25, `/a"b`)
+
+	want := `# HELP test_duration_seconds 耗时
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/a\"b",le="0.005"} 0
+test_duration_seconds_bucket{route="/a\"b",le="0.5"} 1
+test_duration_seconds_bucket{route="/a\"b",le="1"} 1
+test_duration_seconds_bucket{route="/a\"b",le="2.5"} 1
+test_duration_seconds_bucket{route="/a\"b",le="+Inf"} 1
+test_duration_seconds_sum{route="/a\"b"} 0.25
+test_duration_seconds_count{route="/a\"b"} 1
+test_duration_seconds_bucket{route="/download/:name",le="0.005"} 2
+test_duration_seconds_bucket{route="/download/:name",le="0.5"} 3
+test_duration_seconds_bucket{route="/download/:name",le="1"} 4
+test_duration_seconds_bucket{route="/download/:name",le="2.5"} 4
+test_duration_seconds_bucket{route="/download/:name",le="+Inf"} 5
+test_duration_seconds_sum{route="/download/:name"} 4.306
+test_duration_seconds_count{route="/download/:name"} 5
+`
+	if got := render(h); got != want {
+		t.Fatalf("输出不一致:\n%s\n期望:\n%s", got, want)
+	}
+}
+
+func TestHistogramWithoutLabels(t *testing.T) {
+	h := NewHistogram("test_scan_seconds", "扫描耗时", []float64{1})
+	h.Observe(2)
+
+	want := `# HELP test_scan_seconds 扫描耗时
+# TYPE test_scan_seconds histogram
+test_scan_seconds_bucket{le="1"} 0
+test_scan_seconds_bucket{le="+Inf"} 1
+test_scan_seconds_sum 2
+test_scan_seconds_count 1
+`
+	if got := render(h); got != want {
+		t.Fatalf("输出不一致:\n%s\n期望:\n%s", got, want)
+	}
+}
+
+func TestRegistrySortsFamilies(t *testing.T) {
+	r := NewRegistry()
+	r.register(&GaugeFunc{family: family{metricName: "z_metric", help: "z"}, fn: func() float64 { return 1 }})
+	r.register(&GaugeFunc{family: family{metricName: "a_metric", help: "a"}, fn: func() float64 { return 2 }})
+
+	var builder strings.Builder
+	r.WritePrometheus(&builder)
+	want := `# HELP a_metric a
+# TYPE a_metric gauge
+a_metric 2
+# HELP z_metric z
+# TYPE z_metric gauge
+z_metric 1
+`
+	if got := builder.String(); got != want {
+		t.Fatalf("输出不一致:\n%s\n期望:\n%s", got, want)
+	}
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	c := NewCounter("test_mismatch_total", "标签数量不一致", "pack")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("标签数量不一致时应 panic")
+		}
+	}()
+	c.Inc()
+}
@@ -0,0 +1,56 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic 替换 path 的内容：先写入同目录下以 . 开头的临时文件并同步到磁盘，再重命名为 path。
+// 进程或系统崩溃后 path 要么是原来的内容，要么是完整的新内容。
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return writeFileAtomicWith(path, perm, func(file *os.File) error {
+		_, err := file.Write(data)
+		return err
+	})
+}
+
+// writeFileAtomicWith 与 WriteFileAtomic 相同，内容由 write 写入临时文件；
+// write 返回错误时临时文件被删除，path 保持不变。
+func writeFileAtomicWith(path string, perm os.FileMode, write func(file *os.File) error) error {
+	dir := filepath.Dir(path)
+	tmpFile, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	err = write(tmpFile)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// os.CreateTemp 创建的文件权限为 0600
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir 将目录项的变化（重命名）同步到磁盘，部分平台不支持对目录调用 Sync，忽略错误
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,145 @@
+package pack
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+)
+
+const DefaultCompressionLevel = flate.DefaultCompression
+
+// 1980-01-01 00:00:00，ZIP (MS-DOS) 时间格式能表示的最早时间
+const (
+	zipFixedDate = 1<<5 | 1
+	zipFixedTime = 0
+)
+
+// zipBuilderVersion 在打包方式变化时递增
+const zipBuilderVersion = 1
+
+// ZipBuilder 将目录资源包打包为可复现的 ZIP：条目按路径排序、使用 / 分隔、
+// 时间戳固定且不写入扩展字段，相同内容与压缩级别总是产生相同的字节。
+// 服务器与离线的 build 子命令共用此实现。
+type ZipBuilder struct {
+	compressionLevel int
+}
+
+type BuildResult struct {
+	Size    int64
+	Digests Digests
+}
+
+func NewZipBuilder(compressionLevel int) (*ZipBuilder, error) {
+	if compressionLevel < flate.HuffmanOnly || compressionLevel > flate.BestCompression {
+		return nil, fmt.Errorf("无效的压缩级别: %d", compressionLevel)
+	}
+	return &ZipBuilder{compressionLevel: compressionLevel}, nil
+}
+
+func (b *ZipBuilder) CompressionLevel() int {
+	return b.compressionLevel
+}
+
+// buildID 标识打包结果所依赖的实现：打包方式、Go 版本（compress/flate 的输出可能随之变化）与压缩级别，
+// 任何一项变化都可能让相同的目录产生不同的 ZIP
+func (b *ZipBuilder) buildID() string {
+	return fmt.Sprintf("v%d/%s/level=%d", zipBuilderVersion, runtime.Version(), b.compressionLevel)
+}
+
+func (b *ZipBuilder) Build(w io.Writer, dirPath string) (*BuildResult, error) {
+	files, err := collectPackFiles(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dw := newDigestWriter()
+	counter := &countingWriter{}
+	zipWriter := zip.NewWriter(io.MultiWriter(w, dw, counter))
+	zipWriter.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, b.compressionLevel)
+	})
+
+	for _, name := range files {
+		if err := b.writeEntry(zipWriter, dirPath, name); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	return &BuildResult{
+		Size:    counter.n,
+		Digests: dw.Digests(),
+	}, nil
+}
+
+func (b *ZipBuilder) writeEntry(zipWriter *zip.Writer, dirPath, name string) error {
+	file, err := os.Open(filepath.Join(dirPath, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	method := zip.Deflate
+	if b.compressionLevel == flate.NoCompression {
+		method = zip.Store
+	}
+
+	// Modified 保持为零值，避免写入扩展时间戳字段
+	header := &zip.FileHeader{
+		Name:   name,
+		Method: method,
+	}
+	header.ModifiedDate = zipFixedDate
+	header.ModifiedTime = zipFixedTime
+
+	entry, err := zipWriter.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(entry, file)
+	return err
+}
+
+// collectPackFiles 返回目录中所有普通文件的相对路径（使用 / 分隔），按字典序排列
+func collectPackFiles(dirPath string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dirPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(dirPath, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(relPath))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	cw.n += int64(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,140 @@
+package pack
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var builderTestFiles = []struct {
+	path    string
+	content string
+}{
+	{"pack.mcmeta", `{"pack":{"pack_format":15,"description":"test"}}`},
+	{"pack.png", "png"},
+	{"assets/minecraft/textures/block/stone.png", "stone"},
+	{"assets/minecraft/textures/block/dirt.png", "dirt"},
+	{"assets/minecraft/models/block/stone.json", `{"parent":"block/cube_all"}`},
+	{"assets/custom/lang/en_us.json", `{"key":"value"}`},
+}
+
+// writeBuilderTestDir 按 order 的顺序创建文件并把修改时间设为 modTime
+func writeBuilderTestDir(t *testing.T, order []int, modTime time.Time) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, i := range order {
+		file := builderTestFiles[i]
+		path := filepath.Join(dir, filepath.FromSlash(file.path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(file.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func buildZip(t *testing.T, level int, dir string) ([]byte, *BuildResult) {
+	t.Helper()
+	builder, err := NewZipBuilder(level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	result, err := builder.Build(&buf, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), result
+}
+
+func TestZipBuilderReproducible(t *testing.T) {
+	tests := []struct {
+		name  string
+		level int
+	}{
+		{"default", DefaultCompressionLevel},
+		{"store", flate.NoCompression},
+		{"best", flate.BestCompression},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := writeBuilderTestDir(t, []int{0, 1, 2, 3, 4, 5}, time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+			second := writeBuilderTestDir(t, []int{5, 3, 4, 1, 2, 0}, time.Date(2024, 6, 7, 8, 9, 10, 0, time.Local))
+
+			firstBytes, firstResult := buildZip(t, tt.level, first)
+			secondBytes, secondResult := buildZip(t, tt.level, second)
+
+			if !bytes.Equal(firstBytes, secondBytes) {
+				t.Fatal("相同内容的目录生成了不同的 ZIP")
+			}
+			if *firstResult != *secondResult {
+				t.Fatalf("摘要不一致: %+v != %+v", firstResult, secondResult)
+			}
+			if firstResult.Size != int64(len(firstBytes)) {
+				t.Fatalf("Size = %d，实际写入 %d 字节", firstResult.Size, len(firstBytes))
+			}
+			dw := newDigestWriter()
+			dw.Write(firstBytes)
+			if digests := dw.Digests(); digests != firstResult.Digests {
+				t.Fatalf("Digests = %+v，期望 %+v", firstResult.Digests, digests)
+			}
+		})
+	}
+}
+
+func TestZipBuilderEntries(t *testing.T) {
+	dir := writeBuilderTestDir(t, []int{0, 1, 2, 3, 4, 5}, time.Now())
+	data, _ := buildZip(t, DefaultCompressionLevel, dir)
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"assets/custom/lang/en_us.json",
+		"assets/minecraft/models/block/stone.json",
+		"assets/minecraft/textures/block/dirt.png",
+		"assets/minecraft/textures/block/stone.png",
+		"pack.mcmeta",
+		"pack.png",
+	}
+	if len(reader.File) != len(want) {
+		t.Fatalf("条目数 = %d，期望 %d", len(reader.File), len(want))
+	}
+	for i, file := range reader.File {
+		if file.Name != want[i] {
+			t.Errorf("条目 %d = %q，期望 %q", i, file.Name, want[i])
+		}
+		if file.ModifiedDate != zipFixedDate || file.ModifiedTime != zipFixedTime {
+			t.Errorf("%s 的时间戳未固定", file.Name)
+		}
+		if len(file.Extra) != 0 {
+			t.Errorf("%s 包含扩展字段", file.Name)
+		}
+	}
+}
+
+func TestZipBuilderContentChangesHash(t *testing.T) {
+	dir := writeBuilderTestDir(t, []int{0, 1, 2, 3, 4, 5}, time.Now())
+	_, before := buildZip(t, DefaultCompressionLevel, dir)
+
+	if err := os.WriteFile(filepath.Join(dir, "pack.png"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, after := buildZip(t, DefaultCompressionLevel, dir)
+
+	if before.Digests.SHA1 == after.Digests.SHA1 {
+		t.Fatal("内容变化后 SHA-1 未变化")
+	}
+}
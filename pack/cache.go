@@ -0,0 +1,250 @@
+package pack
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var ErrArtifactOutdated = errors.New("资源包内容已变化，等待重新扫描")
+
+// zipArtifact 是目录资源包打包生成的 ZIP 文件，在 zipCache 中以其 SHA-1 为键
+type zipArtifact struct {
+	path    string
+	size    int64
+	digests Digests
+}
+
+// zipBuild 是正在进行的一次打包，同一 SHA-1 的并发请求等待 done 后共享结果
+type zipBuild struct {
+	done     chan struct{}
+	artifact *zipArtifact
+	err      error
+}
+
+// zipSource 记录目录资源包上次打包时的目录指纹及对应产物的 SHA-1
+type zipSource struct {
+	fingerprint string
+	sha1        string
+}
+
+func (pm *PacksManager) artifactPath(sha1 string) string {
+	return filepath.Join(pm.tempDir, sha1+".zip")
+}
+
+func (pm *PacksManager) buildDirectoryZip(dirPath string) (*zipArtifact, error) {
+	startTime := time.Now()
+	defer func() {
+		zipBuildDuration.Observe(time.Since(startTime).Seconds())
+	}()
+
+	tmpFile, err := os.CreateTemp(pm.tempDir, ".build-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	tmpPath := tmpFile.Name()
+
+	result, err := pm.zipBuilder.Build(tmpFile, dirPath)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+
+	zipPath := pm.artifactPath(result.Digests.SHA1)
+	if err := os.Rename(tmpPath, zipPath); err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+
+	artifact := &zipArtifact{
+		path:    zipPath,
+		size:    result.Size,
+		digests: result.Digests,
+	}
+
+	pm.zipCacheMutex.Lock()
+	pm.zipCache[artifact.digests.SHA1] = artifact
+	pm.zipCacheMutex.Unlock()
+
+	pm.logger.Info("已创建临时文件", zap.String("path", zipPath), zap.String("sha1", artifact.digests.SHA1))
+	return artifact, nil
+}
+
+// directoryArtifact 返回目录当前内容对应的 ZIP，目录指纹未变化时直接复用缓存
+func (pm *PacksManager) directoryArtifact(name, dirPath string) (*zipArtifact, error) {
+	fingerprint, err := pm.calculateDirectoryFingerprint(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.zipCacheMutex.RLock()
+	source, ok := pm.zipSources[name]
+	artifact := pm.zipCache[source.sha1]
+	pm.zipCacheMutex.RUnlock()
+
+	if ok && source.fingerprint == fingerprint && artifact != nil && fileExists(artifact.path) {
+		zipCacheRequests.Inc("hit")
+		return artifact, nil
+	}
+	zipCacheRequests.Inc("miss")
+
+	artifact, err = pm.buildDirectoryZip(dirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.zipCacheMutex.Lock()
+	pm.zipSources[name] = zipSource{fingerprint: fingerprint, sha1: artifact.digests.SHA1}
+	pm.zipCacheMutex.Unlock()
+
+	return artifact, nil
+}
+
+// ArtifactPath 返回下载资源包时实际发送的文件。只会返回与公布的 SHA-1 一致的内容，
+// 文件在两次扫描之间被修改时返回 ErrArtifactOutdated 并通过防抖器重新加载该资源包，
+// 大量并发下载同一个过期资源包时只会重新加载一次。
+func (pm *PacksManager) ArtifactPath(rp *ResourcePack) (string, error) {
+	if !rp.IsDirectory {
+		stat, err := os.Stat(rp.Path)
+		if err != nil {
+			return "", err
+		}
+		if stat.Size() != rp.Size || !stat.ModTime().Equal(rp.LastModified) {
+			pm.reloadPackLater(rp.Name)
+			return "", ErrArtifactOutdated
+		}
+		return rp.Path, nil
+	}
+
+	pm.zipCacheMutex.RLock()
+	artifact := pm.zipCache[rp.SHA1]
+	pm.zipCacheMutex.RUnlock()
+
+	if artifact != nil && fileExists(artifact.path) {
+		zipCacheRequests.Inc("hit")
+		return artifact.path, nil
+	}
+	zipCacheRequests.Inc("miss")
+
+	artifact, err := pm.rebuildArtifact(rp)
+	if err != nil {
+		return "", fmt.Errorf("重新打包失败: %w", err)
+	}
+	if artifact.digests.SHA1 != rp.SHA1 {
+		// 索引中的 SHA-1 可能来自内容相同但打包结果不同的旧版本，必须重新打包而不是复用
+		pm.index.forget(rp.Path)
+		pm.reloadPackLater(rp.Name)
+		return "", ErrArtifactOutdated
+	}
+
+	return artifact.path, nil
+}
+
+// rebuildArtifact 重新打包缓存中缺失的目录资源包。同一 SHA-1 同时只打包一次，
+// 其余请求等待并共享结果，避免大量并发下载各自打包同一个目录。
+func (pm *PacksManager) rebuildArtifact(rp *ResourcePack) (*zipArtifact, error) {
+	pm.zipBuildsMu.Lock()
+	if build, ok := pm.zipBuilds[rp.SHA1]; ok {
+		pm.zipBuildsMu.Unlock()
+		<-build.done
+		return build.artifact, build.err
+	}
+	build := &zipBuild{done: make(chan struct{})}
+	pm.zipBuilds[rp.SHA1] = build
+	pm.zipBuildsMu.Unlock()
+
+	defer func() {
+		pm.zipBuildsMu.Lock()
+		delete(pm.zipBuilds, rp.SHA1)
+		pm.zipBuildsMu.Unlock()
+		close(build.done)
+	}()
+
+	// 检查缓存与登记打包之间，上一次打包可能刚刚完成
+	pm.zipCacheMutex.RLock()
+	artifact := pm.zipCache[rp.SHA1]
+	pm.zipCacheMutex.RUnlock()
+	if artifact != nil && fileExists(artifact.path) {
+		build.artifact = artifact
+		return artifact, nil
+	}
+
+	build.artifact, build.err = pm.buildDirectoryZip(rp.Path)
+	return build.artifact, build.err
+}
+
+// evictStaleArtifacts 删除不再被任何目录资源包引用、且已超过宽限期的 ZIP，调用方需持有 pm.mu
+func (pm *PacksManager) evictStaleArtifacts() {
+	referenced := make(map[string]bool)
+	for _, rp := range pm.packs {
+		if rp.IsDirectory {
+			referenced[rp.SHA1] = true
+		}
+	}
+	for sha1, record := range pm.superseded {
+		if pm.inGracePeriod(record) {
+			referenced[sha1] = true
+		}
+	}
+
+	pm.zipCacheMutex.Lock()
+	defer pm.zipCacheMutex.Unlock()
+
+	for name := range pm.zipSources {
+		if rp, ok := pm.packs[name]; !ok || !rp.IsDirectory {
+			delete(pm.zipSources, name)
+		}
+	}
+
+	for sha1, artifact := range pm.zipCache {
+		if referenced[sha1] {
+			continue
+		}
+		pm.removeArtifactLocked(sha1, artifact)
+	}
+
+	// 校验报告对 ZIP 与目录资源包都有效，不能按打包产物的引用情况清理
+	current := make(map[string]bool, len(pm.packs))
+	for _, rp := range pm.packs {
+		current[rp.SHA1] = true
+	}
+
+	pm.validationsMu.Lock()
+	defer pm.validationsMu.Unlock()
+	for sha1 := range pm.validations {
+		if !current[sha1] && !referenced[sha1] {
+			delete(pm.validations, sha1)
+		}
+	}
+}
+
+func (pm *PacksManager) cleanupAllZipCache() {
+	pm.zipCacheMutex.Lock()
+	defer pm.zipCacheMutex.Unlock()
+
+	for sha1, artifact := range pm.zipCache {
+		pm.removeArtifactLocked(sha1, artifact)
+	}
+	pm.zipSources = make(map[string]zipSource)
+}
+
+func (pm *PacksManager) removeArtifactLocked(sha1 string, artifact *zipArtifact) {
+	if err := os.Remove(artifact.path); err != nil && !os.IsNotExist(err) {
+		pm.logger.Warn("删除临时文件失败", zap.String("path", artifact.path), zap.Error(err))
+	} else {
+		pm.logger.Info("已删除临时文件", zap.String("path", artifact.path))
+	}
+	delete(pm.zipCache, sha1)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
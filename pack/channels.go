@@ -0,0 +1,161 @@
+package pack
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"go.uber.org/zap"
+)
+
+// ChannelCurrent 是始终指向资源包当前版本的保留渠道，发布到该渠道即回滚到对应版本
+const ChannelCurrent = "current"
+
+var (
+	ErrChannelNotFound = errors.New("发布渠道不存在")
+	ErrInvalidChannel  = errors.New("发布渠道名称无效")
+)
+
+var channelNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+type ChannelInfo struct {
+	Name string `json:"name"`
+	SHA1 string `json:"sha1"`
+}
+
+func validateChannelName(channel string) error {
+	if !channelNamePattern.MatchString(channel) {
+		return fmt.Errorf("%w: %q", ErrInvalidChannel, channel)
+	}
+	return nil
+}
+
+// GetChannels 返回资源包的所有发布渠道，包括始终存在的 current
+func (pm *PacksManager) GetChannels(name string) ([]ChannelInfo, error) {
+	current := pm.GetPack(name)
+	if current == nil {
+		return nil, ErrPackNotFound
+	}
+
+	channels := []ChannelInfo{{Name: ChannelCurrent, SHA1: current.SHA1}}
+
+	pm.history.mu.Lock()
+	if history, ok := pm.history.packs[name]; ok {
+		for channel, sha1 := range history.Channels {
+			channels = append(channels, ChannelInfo{Name: channel, SHA1: sha1})
+		}
+	}
+	pm.history.mu.Unlock()
+
+	sort.Slice(channels[1:], func(i, j int) bool {
+		return channels[i+1].Name < channels[j+1].Name
+	})
+	return channels, nil
+}
+
+// GetPackChannel 返回渠道指向的资源包版本
+func (pm *PacksManager) GetPackChannel(name, channel string) (*ResourcePack, error) {
+	if channel == ChannelCurrent {
+		if rp := pm.GetPack(name); rp != nil {
+			return rp, nil
+		}
+		return nil, ErrPackNotFound
+	}
+
+	pm.history.mu.Lock()
+	sha1 := ""
+	if history, ok := pm.history.packs[name]; ok {
+		sha1 = history.Channels[channel]
+	}
+	pm.history.mu.Unlock()
+
+	if sha1 == "" {
+		return nil, ErrChannelNotFound
+	}
+	return pm.GetPackVersion(name, sha1)
+}
+
+// SetChannel 将渠道指向资源包的某个历史版本
+func (pm *PacksManager) SetChannel(name, channel, sha1 string) (*ResourcePack, error) {
+	if !pm.history.enabled() {
+		return nil, ErrHistoryDisabled
+	}
+	if channel == ChannelCurrent {
+		return pm.RollbackPack(name, sha1)
+	}
+	if err := validateChannelName(channel); err != nil {
+		return nil, err
+	}
+
+	rp, err := pm.GetPackVersion(name, sha1)
+	if err != nil {
+		return nil, err
+	}
+
+	// 资源包目录中已有的版本只在变化时记录，发布到渠道时才需要保存当前版本
+	if source := pm.filesystemPack(name); source != nil && source.SHA1 == rp.SHA1 {
+		if _, ok := pm.history.find(name, rp.SHA1); !ok {
+			pm.recordVersion(source, "")
+		}
+	}
+
+	hs := pm.history
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	history, ok := hs.packs[name]
+	if !ok || !containsVersion(history.Versions, sha1) {
+		return nil, fmt.Errorf("%w: 版本 %s 不在版本历史中", ErrPackNotFound, sha1)
+	}
+	if history.Channels == nil {
+		history.Channels = make(map[string]string)
+	}
+	history.Channels[channel] = sha1
+	if err := hs.saveLocked(); err != nil {
+		return nil, fmt.Errorf("保存版本历史失败: %w", err)
+	}
+
+	pm.logger.Info("已更新发布渠道", zap.String("name", name), zap.String("channel", channel), zap.String("sha1", sha1))
+	return rp, nil
+}
+
+func (pm *PacksManager) DeleteChannel(name, channel string) error {
+	if channel == ChannelCurrent {
+		return fmt.Errorf("%w: 无法删除 current 渠道", ErrInvalidChannel)
+	}
+
+	hs := pm.history
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	history, ok := hs.packs[name]
+	if !ok || history.Channels[channel] == "" {
+		return ErrChannelNotFound
+	}
+	delete(history.Channels, channel)
+	if err := hs.saveLocked(); err != nil {
+		return fmt.Errorf("保存版本历史失败: %w", err)
+	}
+
+	pm.logger.Info("已删除发布渠道", zap.String("name", name), zap.String("channel", channel))
+	return nil
+}
+
+// PromoteChannel 将 from 渠道当前指向的版本发布到 to 渠道，例如 beta -> current
+func (pm *PacksManager) PromoteChannel(name, from, to string) (*ResourcePack, error) {
+	rp, err := pm.GetPackChannel(name, from)
+	if err != nil {
+		return nil, err
+	}
+	return pm.SetChannel(name, to, rp.SHA1)
+}
+
+func containsVersion(versions []VersionRecord, sha1 string) bool {
+	for _, version := range versions {
+		if version.SHA1 == sha1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package pack
+
+import (
+	"sync"
+	"time"
+)
+
+// debouncer 按键合并短时间内的多次触发，在最后一次触发后静默 delay 才执行（后沿触发）。
+// 执行期间的新触发会在本次执行结束后重新计时，保证最后一次变化一定会被处理；
+// 同一个键两次执行的间隔不少于 cooldown。
+type debouncer struct {
+	delay    time.Duration
+	cooldown time.Duration
+	run      func(key string)
+
+	mu      sync.Mutex
+	entries map[string]*debounceEntry
+	stopped bool
+}
+
+type debounceEntry struct {
+	timer      *time.Timer
+	generation uint64
+	running    bool
+	dirty      bool
+	lastRun    time.Time
+}
+
+func newDebouncer(delay, cooldown time.Duration, run func(key string)) *debouncer {
+	return &debouncer{
+		delay:    delay,
+		cooldown: cooldown,
+		run:      run,
+		entries:  make(map[string]*debounceEntry),
+	}
+}
+
+func (d *debouncer) trigger(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+	entry, ok := d.entries[key]
+	if !ok {
+		entry = &debounceEntry{}
+		d.entries[key] = entry
+	}
+	if entry.running {
+		entry.dirty = true
+		return
+	}
+
+	wait := d.delay
+	if remaining := d.cooldown - time.Since(entry.lastRun); remaining > wait {
+		wait = remaining
+	}
+	if entry.timer != nil {
+		entry.timer.Stop()
+	}
+	entry.generation++
+	generation := entry.generation
+	entry.timer = time.AfterFunc(wait, func() { d.fire(key, generation) })
+}
+
+// fire 由计时器调用；计时器已被新的触发替换时直接返回，由新的计时器执行
+func (d *debouncer) fire(key string, generation uint64) {
+	d.mu.Lock()
+	entry := d.entries[key]
+	if d.stopped || entry == nil || entry.generation != generation {
+		d.mu.Unlock()
+		return
+	}
+	entry.timer = nil
+	entry.running = true
+	d.mu.Unlock()
+
+	d.run(key)
+
+	d.mu.Lock()
+	entry.running = false
+	entry.lastRun = time.Now()
+	dirty := entry.dirty
+	entry.dirty = false
+	d.mu.Unlock()
+
+	if dirty {
+		d.trigger(key)
+	}
+}
+
+// stop 取消所有等待中的执行，正在执行的不受影响
+func (d *debouncer) stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = true
+	for _, entry := range d.entries {
+		if entry.timer != nil {
+			entry.timer.Stop()
+		}
+	}
+}
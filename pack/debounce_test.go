@@ -0,0 +1,196 @@
+package pack
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type debounceRecorder struct {
+	mu   sync.Mutex
+	runs []debounceRun
+}
+
+type debounceRun struct {
+	key string
+	at  time.Time
+}
+
+func (r *debounceRecorder) run(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, debounceRun{key: key, at: time.Now()})
+}
+
+func (r *debounceRecorder) snapshot() []debounceRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]debounceRun(nil), r.runs...)
+}
+
+func (r *debounceRecorder) count(key string) int {
+	n := 0
+	for _, run := range r.snapshot() {
+		if run.key == key {
+			n++
+		}
+	}
+	return n
+}
+
+func TestDebouncerCoalescesBursts(t *testing.T) {
+	const delay = 50 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		triggers []string
+		want     map[string]int
+	}{
+		{"single trigger", []string{"a"}, map[string]int{"a": 1}},
+		{"burst on one key", []string{"a", "a", "a", "a", "a"}, map[string]int{"a": 1}},
+		{"keys are independent", []string{"a", "b", "a", "b", fullScanKey}, map[string]int{"a": 1, "b": 1, fullScanKey: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &debounceRecorder{}
+			d := newDebouncer(delay, 0, recorder.run)
+			defer d.stop()
+
+			lastTrigger := make(map[string]time.Time)
+			for _, key := range tt.triggers {
+				d.trigger(key)
+				lastTrigger[key] = time.Now()
+				time.Sleep(delay / 5)
+			}
+			time.Sleep(4 * delay)
+
+			for key, want := range tt.want {
+				if got := recorder.count(key); got != want {
+					t.Errorf("键 %q 执行了 %d 次，期望 %d", key, got, want)
+				}
+			}
+			// 后沿触发：执行发生在该键最后一次触发静默 delay 之后
+			for _, run := range recorder.snapshot() {
+				if quiet := run.at.Sub(lastTrigger[run.key]); quiet < delay*9/10 {
+					t.Errorf("键 %q 在最后一次触发后 %v 执行，期望至少 %v", run.key, quiet, delay)
+				}
+			}
+		})
+	}
+}
+
+func TestDebouncerTriggerDuringRun(t *testing.T) {
+	const delay = 20 * time.Millisecond
+
+	tests := []struct {
+		name           string
+		triggersDuring int
+		wantRuns       int
+	}{
+		{"no trigger during run", 0, 1},
+		{"one trigger during run reruns", 1, 2},
+		{"many triggers during run rerun once", 5, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{}, 10)
+			release := make(chan struct{})
+			var mu sync.Mutex
+			runs := 0
+			d := newDebouncer(delay, 0, func(key string) {
+				mu.Lock()
+				runs++
+				first := runs == 1
+				mu.Unlock()
+				started <- struct{}{}
+				if first {
+					<-release
+				}
+			})
+			defer d.stop()
+
+			d.trigger("a")
+			select {
+			case <-started:
+			case <-time.After(time.Second):
+				t.Fatal("第一次执行未开始")
+			}
+			for i := 0; i < tt.triggersDuring; i++ {
+				d.trigger("a")
+			}
+			// 执行期间的触发不能启动并发的第二次执行
+			time.Sleep(3 * delay)
+			mu.Lock()
+			if runs != 1 {
+				t.Fatalf("执行期间开始了第 %d 次执行", runs)
+			}
+			mu.Unlock()
+
+			close(release)
+			time.Sleep(5 * delay)
+			mu.Lock()
+			defer mu.Unlock()
+			if runs != tt.wantRuns {
+				t.Fatalf("执行了 %d 次，期望 %d", runs, tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestDebouncerCooldown(t *testing.T) {
+	const (
+		delay    = 10 * time.Millisecond
+		cooldown = 150 * time.Millisecond
+	)
+
+	tests := []struct {
+		name        string
+		secondAfter time.Duration
+		wantGap     time.Duration
+	}{
+		{"trigger inside cooldown waits for it", 20 * time.Millisecond, cooldown},
+		{"trigger after cooldown only waits delay", cooldown + 50*time.Millisecond, cooldown + 50*time.Millisecond + delay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &debounceRecorder{}
+			d := newDebouncer(delay, cooldown, recorder.run)
+			defer d.stop()
+
+			d.trigger("a")
+			time.Sleep(delay + 20*time.Millisecond)
+			if recorder.count("a") != 1 {
+				t.Fatal("第一次触发未在 delay 后执行")
+			}
+
+			time.Sleep(tt.secondAfter - 20*time.Millisecond)
+			d.trigger("a")
+			time.Sleep(cooldown + 100*time.Millisecond)
+
+			runs := recorder.snapshot()
+			if len(runs) != 2 {
+				t.Fatalf("执行了 %d 次，期望 2", len(runs))
+			}
+			if gap := runs[1].at.Sub(runs[0].at); gap < tt.wantGap*9/10 {
+				t.Fatalf("两次执行间隔 %v，期望至少 %v", gap, tt.wantGap)
+			}
+		})
+	}
+}
+
+func TestDebouncerStopCancelsPending(t *testing.T) {
+	recorder := &debounceRecorder{}
+	d := newDebouncer(20*time.Millisecond, 0, recorder.run)
+
+	d.trigger("a")
+	d.stop()
+	d.trigger("b")
+	time.Sleep(80 * time.Millisecond)
+
+	if runs := recorder.snapshot(); len(runs) != 0 {
+		t.Fatalf("stop 之后仍执行了 %d 次", len(runs))
+	}
+}
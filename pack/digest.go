@@ -0,0 +1,80 @@
+package pack
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	HashMD5    = "md5"
+	HashSHA1   = "sha1"
+	HashSHA256 = "sha256"
+)
+
+type Digests struct {
+	MD5    string `json:"md5"`
+	SHA1   string `json:"sha1"`
+	SHA256 string `json:"sha256"`
+}
+
+func (d Digests) Get(algo string) (string, bool) {
+	switch strings.ToLower(algo) {
+	case HashMD5:
+		return d.MD5, true
+	case HashSHA1, "sha-1":
+		return d.SHA1, true
+	case HashSHA256, "sha-256":
+		return d.SHA256, true
+	}
+	return "", false
+}
+
+// digestWriter 在写入数据的同时计算所有支持的摘要
+type digestWriter struct {
+	md5    hash.Hash
+	sha1   hash.Hash
+	sha256 hash.Hash
+	writer io.Writer
+}
+
+func newDigestWriter() *digestWriter {
+	dw := &digestWriter{
+		md5:    md5.New(),
+		sha1:   sha1.New(),
+		sha256: sha256.New(),
+	}
+	dw.writer = io.MultiWriter(dw.md5, dw.sha1, dw.sha256)
+	return dw
+}
+
+func (dw *digestWriter) Write(p []byte) (int, error) {
+	return dw.writer.Write(p)
+}
+
+func (dw *digestWriter) Digests() Digests {
+	return Digests{
+		MD5:    hex.EncodeToString(dw.md5.Sum(nil)),
+		SHA1:   hex.EncodeToString(dw.sha1.Sum(nil)),
+		SHA256: hex.EncodeToString(dw.sha256.Sum(nil)),
+	}
+}
+
+func calculateFileDigests(filePath string) (Digests, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return Digests{}, err
+	}
+	defer file.Close()
+
+	dw := newDigestWriter()
+	if _, err := io.Copy(dw, file); err != nil {
+		return Digests{}, err
+	}
+	return dw.Digests(), nil
+}
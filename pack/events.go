@@ -0,0 +1,158 @@
+package pack
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	EventPackAdded     = "pack.added"
+	EventPackUpdated   = "pack.updated"
+	EventPackRemoved   = "pack.removed"
+	EventScanCompleted = "scan.completed"
+	// EventStreamReset 表示客户端请求的事件已不在缓冲区中，需要重新获取完整的资源包列表
+	EventStreamReset = "stream.reset"
+)
+
+const (
+	eventBufferSize     = 1024
+	subscriberQueueSize = 64
+)
+
+type Event struct {
+	ID   uint64                 `json:"id"`
+	Type string                 `json:"type"`
+	Time time.Time              `json:"time"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// eventBroker 将资源包变化分发给订阅者，并保留最近的事件供断线重连后补发。
+// 事件 ID 以启动时的毫秒时间戳为起点递增，重启后 ID 不会回退。
+type eventBroker struct {
+	mu          sync.Mutex
+	nextID      uint64 // 最后发布的事件 ID
+	buffer      []Event
+	subscribers map[chan Event]struct{}
+}
+
+func newEventBroker() *eventBroker {
+	return &eventBroker{
+		nextID:      uint64(time.Now().UnixMilli()) * 1000,
+		subscribers: make(map[chan Event]struct{}),
+	}
+}
+
+func (eb *eventBroker) lastID() uint64 {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	return eb.nextID
+}
+
+// StartupEventID 返回启动扫描之前的事件 ID。以它订阅可以补发启动扫描中与上次运行相比的变化；
+// 没有上次运行的资源包索引时返回启动扫描之后的 ID，避免把所有资源包当作新增。
+func (pm *PacksManager) StartupEventID() uint64 {
+	return pm.startupEventID
+}
+
+// publish 不会阻塞：订阅者的队列已满时直接断开，客户端重连后通过 Last-Event-ID 补发
+func (eb *eventBroker) publish(eventType string, data map[string]interface{}) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	eb.nextID++
+	event := Event{ID: eb.nextID, Type: eventType, Time: time.Now(), Data: data}
+
+	eb.buffer = append(eb.buffer, event)
+	if len(eb.buffer) > eventBufferSize {
+		eb.buffer = eb.buffer[len(eb.buffer)-eventBufferSize:]
+	}
+
+	for ch := range eb.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(eb.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe 订阅资源包事件。lastID 不为 0 时先返回其后的缓冲事件；
+// 所需事件已被丢弃时返回一个 stream.reset 事件。取消订阅后通道会被关闭。
+func (pm *PacksManager) Subscribe(lastID uint64) ([]Event, <-chan Event, func()) {
+	eb := pm.events
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+
+	var replay []Event
+	if lastID != 0 && lastID < eb.nextID {
+		if len(eb.buffer) == 0 || eb.buffer[0].ID > lastID+1 {
+			replay = append(replay, Event{
+				ID:   eb.nextID,
+				Type: EventStreamReset,
+				Time: time.Now(),
+				Data: map[string]interface{}{"reason": "请求的事件已过期"},
+			})
+		} else {
+			for _, event := range eb.buffer {
+				if event.ID > lastID {
+					replay = append(replay, event)
+				}
+			}
+		}
+	}
+
+	ch := make(chan Event, subscriberQueueSize)
+	eb.subscribers[ch] = struct{}{}
+
+	cancel := func() {
+		eb.mu.Lock()
+		defer eb.mu.Unlock()
+		if _, ok := eb.subscribers[ch]; ok {
+			delete(eb.subscribers, ch)
+			close(ch)
+		}
+	}
+	return replay, ch, cancel
+}
+
+// publishChanges 比较同一组资源包变化前后的版本并发布对应事件，返回新增、更新、移除的数量
+func (pm *PacksManager) publishChanges(before, after map[string]*ResourcePack) (int, int, int) {
+	var added, updated, removed int
+	for name, rp := range after {
+		old, ok := before[name]
+		switch {
+		case !ok:
+			added++
+			pm.events.publish(EventPackAdded, packEventData(rp))
+		case old.SHA1 != rp.SHA1:
+			updated++
+			data := packEventData(rp)
+			data["old_sha1"] = old.SHA1
+			data["new_sha1"] = rp.SHA1
+			pm.events.publish(EventPackUpdated, data)
+		}
+	}
+	for name, old := range before {
+		if _, ok := after[name]; !ok {
+			removed++
+			pm.events.publish(EventPackRemoved, map[string]interface{}{
+				"name": name,
+				"sha1": old.SHA1,
+			})
+		}
+	}
+	return added, updated, removed
+}
+
+func packEventData(rp *ResourcePack) map[string]interface{} {
+	return map[string]interface{}{
+		"name":         rp.Name,
+		"sha1":         rp.SHA1,
+		"hash":         rp.Hash,
+		"size":         rp.Size,
+		"download_url": fmt.Sprintf("/download/%s/%s", url.PathEscape(rp.Name), rp.SHA1),
+	}
+}
@@ -0,0 +1,152 @@
+package pack
+
+import (
+	"testing"
+)
+
+func newEventTestManager(published int) (*PacksManager, uint64) {
+	pm := &PacksManager{events: newEventBroker()}
+	first := pm.events.lastID() + 1
+	for i := 0; i < published; i++ {
+		pm.events.publish(EventPackUpdated, map[string]interface{}{"index": i})
+	}
+	return pm, first
+}
+
+func TestSubscribeReplay(t *testing.T) {
+	tests := []struct {
+		name      string
+		published int
+		lastID    func(first, last uint64) uint64
+		wantIDs   func(first, last uint64) []uint64
+		wantReset bool
+	}{
+		{
+			name:      "new subscriber gets no replay",
+			published: 5,
+			lastID:    func(first, last uint64) uint64 { return 0 },
+		},
+		{
+			name:      "up to date subscriber gets no replay",
+			published: 5,
+			lastID:    func(first, last uint64) uint64 { return last },
+		},
+		{
+			name:      "missed events are replayed in order",
+			published: 5,
+			lastID:    func(first, last uint64) uint64 { return first + 1 },
+			wantIDs:   func(first, last uint64) []uint64 { return []uint64{first + 2, first + 3, first + 4} },
+		},
+		{
+			name:      "oldest buffered event is still replayed",
+			published: eventBufferSize + 10,
+			lastID:    func(first, last uint64) uint64 { return last - eventBufferSize },
+			wantIDs: func(first, last uint64) []uint64 {
+				ids := make([]uint64, 0, eventBufferSize)
+				for id := last - eventBufferSize + 1; id <= last; id++ {
+					ids = append(ids, id)
+				}
+				return ids
+			},
+		},
+		{
+			name:      "expired events reset the stream",
+			published: eventBufferSize + 10,
+			lastID:    func(first, last uint64) uint64 { return last - eventBufferSize - 1 },
+			wantReset: true,
+		},
+		{
+			name:      "id from a previous run resets the stream",
+			published: 3,
+			lastID:    func(first, last uint64) uint64 { return first - 100 },
+			wantReset: true,
+		},
+		{
+			name:      "nothing published since previous run resets the stream",
+			published: 0,
+			lastID:    func(first, last uint64) uint64 { return first - 100 },
+			wantReset: true,
+		},
+		{
+			name:      "id ahead of the broker gets no replay",
+			published: 3,
+			lastID:    func(first, last uint64) uint64 { return last + 100 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm, first := newEventTestManager(tt.published)
+			last := pm.events.lastID()
+
+			replay, _, cancel := pm.Subscribe(tt.lastID(first, last))
+			defer cancel()
+
+			if tt.wantReset {
+				if len(replay) != 1 || replay[0].Type != EventStreamReset || replay[0].ID != last {
+					t.Fatalf("replay = %+v，期望 ID 为 %d 的 %s", replay, last, EventStreamReset)
+				}
+				return
+			}
+
+			var want []uint64
+			if tt.wantIDs != nil {
+				want = tt.wantIDs(first, last)
+			}
+			if len(replay) != len(want) {
+				t.Fatalf("补发了 %d 个事件，期望 %d", len(replay), len(want))
+			}
+			for i, event := range replay {
+				if event.ID != want[i] || event.Type != EventPackUpdated {
+					t.Fatalf("第 %d 个补发事件为 %d %s，期望 %d", i, event.ID, event.Type, want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSubscribeReceivesLiveEvents(t *testing.T) {
+	pm, _ := newEventTestManager(2)
+	replay, events, cancel := pm.Subscribe(pm.events.lastID())
+	defer cancel()
+	if len(replay) != 0 {
+		t.Fatalf("补发了 %d 个事件", len(replay))
+	}
+
+	pm.events.publish(EventPackAdded, map[string]interface{}{"name": "pack"})
+	event := <-events
+	if event.Type != EventPackAdded || event.ID != pm.events.lastID() {
+		t.Fatalf("收到 %+v", event)
+	}
+}
+
+func TestSlowSubscriberIsDisconnected(t *testing.T) {
+	pm, _ := newEventTestManager(0)
+	_, events, cancel := pm.Subscribe(0)
+	defer cancel()
+
+	// 队列已满时 publish 不能阻塞，而是断开订阅者
+	for i := 0; i <= subscriberQueueSize; i++ {
+		pm.events.publish(EventPackUpdated, nil)
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	if received != subscriberQueueSize {
+		t.Fatalf("断开前收到 %d 个事件，期望 %d", received, subscriberQueueSize)
+	}
+}
+
+func TestSubscribeCancelClosesChannel(t *testing.T) {
+	pm, _ := newEventTestManager(0)
+	_, events, cancel := pm.Subscribe(0)
+
+	cancel()
+	cancel()
+	if _, ok := <-events; ok {
+		t.Fatal("取消订阅后通道未关闭")
+	}
+	pm.events.publish(EventPackAdded, nil)
+}
@@ -0,0 +1,199 @@
+package pack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FormatVersions 描述一个资源包格式对应的 Minecraft 正式版范围（含两端）
+type FormatVersions struct {
+	Format     int    `json:"format"`
+	MinVersion string `json:"min_version"`
+	MaxVersion string `json:"max_version"`
+}
+
+func (fv FormatVersions) String() string {
+	if fv.MinVersion == fv.MaxVersion {
+		return fv.MinVersion
+	}
+	return fv.MinVersion + " – " + fv.MaxVersion
+}
+
+type VersionRange struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
+func (vr VersionRange) String() string {
+	if vr.Min == vr.Max {
+		return vr.Min
+	}
+	return vr.Min + " – " + vr.Max
+}
+
+var defaultFormatVersions = []FormatVersions{
+	{Format: 1, MinVersion: "1.6.1", MaxVersion: "1.8.9"},
+	{Format: 2, MinVersion: "1.9", MaxVersion: "1.10.2"},
+	{Format: 3, MinVersion: "1.11", MaxVersion: "1.12.2"},
+	{Format: 4, MinVersion: "1.13", MaxVersion: "1.14.4"},
+	{Format: 5, MinVersion: "1.15", MaxVersion: "1.16.1"},
+	{Format: 6, MinVersion: "1.16.2", MaxVersion: "1.16.5"},
+	{Format: 7, MinVersion: "1.17", MaxVersion: "1.17.1"},
+	{Format: 8, MinVersion: "1.18", MaxVersion: "1.18.2"},
+	{Format: 9, MinVersion: "1.19", MaxVersion: "1.19.2"},
+	{Format: 12, MinVersion: "1.19.3", MaxVersion: "1.19.3"},
+	{Format: 13, MinVersion: "1.19.4", MaxVersion: "1.19.4"},
+	{Format: 15, MinVersion: "1.20", MaxVersion: "1.20.1"},
+	{Format: 18, MinVersion: "1.20.2", MaxVersion: "1.20.2"},
+	{Format: 22, MinVersion: "1.20.3", MaxVersion: "1.20.4"},
+	{Format: 32, MinVersion: "1.20.5", MaxVersion: "1.20.6"},
+	{Format: 34, MinVersion: "1.21", MaxVersion: "1.21.1"},
+	{Format: 42, MinVersion: "1.21.2", MaxVersion: "1.21.3"},
+	{Format: 46, MinVersion: "1.21.4", MaxVersion: "1.21.4"},
+	{Format: 55, MinVersion: "1.21.5", MaxVersion: "1.21.5"},
+	{Format: 63, MinVersion: "1.21.6", MaxVersion: "1.21.6"},
+	{Format: 64, MinVersion: "1.21.7", MaxVersion: "1.21.8"},
+	{Format: 69, MinVersion: "1.21.9", MaxVersion: "1.21.10"},
+}
+
+// FormatTable 是资源包格式与 Minecraft 版本的对照表，按格式升序排列
+type FormatTable struct {
+	entries []FormatVersions
+}
+
+func DefaultFormatTable() *FormatTable {
+	return newFormatTable(defaultFormatVersions)
+}
+
+// LoadFormatTable 读取 JSON 数组形式的对照表，并覆盖或补充内置表中的同格式条目，
+// 新版本 Minecraft 发布后无需重新编译即可更新
+func LoadFormatTable(path string) (*FormatTable, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取格式对照表失败: %w", err)
+	}
+
+	var overrides []FormatVersions
+	if err := json.Unmarshal(content, &overrides); err != nil {
+		return nil, fmt.Errorf("解析格式对照表失败: %w", err)
+	}
+
+	merged := make(map[int]FormatVersions)
+	for _, entry := range defaultFormatVersions {
+		merged[entry.Format] = entry
+	}
+	for _, entry := range overrides {
+		if _, err := parseVersion(entry.MinVersion); err != nil {
+			return nil, fmt.Errorf("格式 %d: %w", entry.Format, err)
+		}
+		if _, err := parseVersion(entry.MaxVersion); err != nil {
+			return nil, fmt.Errorf("格式 %d: %w", entry.Format, err)
+		}
+		merged[entry.Format] = entry
+	}
+
+	entries := make([]FormatVersions, 0, len(merged))
+	for _, entry := range merged {
+		entries = append(entries, entry)
+	}
+	return newFormatTable(entries), nil
+}
+
+func newFormatTable(entries []FormatVersions) *FormatTable {
+	sorted := append([]FormatVersions(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Format < sorted[j].Format
+	})
+	return &FormatTable{entries: sorted}
+}
+
+func (t *FormatTable) Entries() []FormatVersions {
+	return append([]FormatVersions(nil), t.entries...)
+}
+
+func (t *FormatTable) Lookup(format int) (FormatVersions, bool) {
+	for _, entry := range t.entries {
+		if entry.Format == format {
+			return entry, true
+		}
+	}
+	return FormatVersions{}, false
+}
+
+// FormatForVersion 返回指定 Minecraft 正式版使用的资源包格式
+func (t *FormatTable) FormatForVersion(version string) (int, error) {
+	target, err := parseVersion(version)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, entry := range t.entries {
+		minVersion, _ := parseVersion(entry.MinVersion)
+		maxVersion, _ := parseVersion(entry.MaxVersion)
+		if compareVersions(target, minVersion) >= 0 && compareVersions(target, maxVersion) <= 0 {
+			return entry.Format, nil
+		}
+	}
+	return 0, fmt.Errorf("未知的 Minecraft 版本: %s", version)
+}
+
+// VersionRange 返回一组格式范围覆盖的 Minecraft 版本区间，没有已知格式时返回 nil
+func (t *FormatTable) VersionRange(ranges []FormatRange) *VersionRange {
+	var result *VersionRange
+	for _, entry := range t.entries {
+		if !formatInRanges(entry.Format, ranges) {
+			continue
+		}
+		if result == nil {
+			result = &VersionRange{Min: entry.MinVersion}
+		}
+		result.Max = entry.MaxVersion
+	}
+	return result
+}
+
+func formatInRanges(format int, ranges []FormatRange) bool {
+	for _, r := range ranges {
+		if r.Contains(format) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseVersion(version string) ([]int, error) {
+	parts := strings.Split(strings.TrimSpace(version), ".")
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("无效的 Minecraft 版本: %s", version)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// compareVersions 比较两个版本号，缺失的部分视为 0，即 1.21 等于 1.21.0
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,26 @@
+package pack
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// OpenPackFS 以 fs.FS 形式打开 ZIP 文件或目录形式的资源包，使用完毕后需调用 close
+func OpenPackFS(packPath string) (fsys fs.FS, close func() error, err error) {
+	stat, err := os.Stat(packPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if stat.IsDir() {
+		return os.DirFS(packPath), func() error { return nil }, nil
+	}
+
+	reader, err := zip.OpenReader(packPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("打开 ZIP 文件失败: %w", err)
+	}
+	return reader, reader.Close, nil
+}
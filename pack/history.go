@@ -0,0 +1,498 @@
+package pack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var ErrHistoryDisabled = errors.New("未启用版本历史")
+
+// VersionRecord 描述资源包的一个历史版本，对应的 ZIP 以 SHA-1 为名保存在状态目录的 blobs 中
+type VersionRecord struct {
+	SHA1        string    `json:"sha1"`
+	SHA256      string    `json:"sha256"`
+	MD5         string    `json:"md5"`
+	Size        int64     `json:"size"`
+	PackFormat  int       `json:"pack_format"`
+	Description string    `json:"description"`
+	Uploader    string    `json:"uploader,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// packHistory 按从新到旧的顺序保存资源包的版本。Pinned 为回滚后对外提供的版本，
+// PinnedFrom 为回滚时资源包目录中的版本，目录中的内容再次变化后回滚自动失效。
+type packHistory struct {
+	Versions   []VersionRecord   `json:"versions"`
+	Pinned     string            `json:"pinned,omitempty"`
+	PinnedFrom string            `json:"pinned_from,omitempty"`
+	Channels   map[string]string `json:"channels,omitempty"`
+}
+
+// retained 判断超出数量限制的版本是否仍需保留
+func (h *packHistory) retained(sha1 string) bool {
+	if sha1 == h.Pinned {
+		return true
+	}
+	for _, channelSHA1 := range h.Channels {
+		if channelSHA1 == sha1 {
+			return true
+		}
+	}
+	return false
+}
+
+type PackVersions struct {
+	Name       string          `json:"name"`
+	Current    string          `json:"current"`
+	RolledBack bool            `json:"rolled_back"`
+	Versions   []VersionRecord `json:"versions"`
+}
+
+// historyStore 保存每个资源包最近的若干版本，数据持久化在 <state_dir>/history.json
+type historyStore struct {
+	dir   string
+	limit int
+	mu    sync.Mutex
+	packs map[string]*packHistory
+	// sources 记录被回滚版本替换的资源包目录中的版本，仅保存在内存中
+	sources map[string]*ResourcePack
+	pinned  map[string]*ResourcePack
+}
+
+func newHistoryStore(dir string, limit int) (*historyStore, error) {
+	hs := &historyStore{
+		dir:     dir,
+		limit:   limit,
+		packs:   make(map[string]*packHistory),
+		sources: make(map[string]*ResourcePack),
+		pinned:  make(map[string]*ResourcePack),
+	}
+	if limit <= 0 {
+		return hs, nil
+	}
+
+	if err := os.MkdirAll(hs.blobDir(), 0755); err != nil {
+		return nil, fmt.Errorf("创建状态目录失败: %w", err)
+	}
+
+	data, err := os.ReadFile(hs.indexPath())
+	if os.IsNotExist(err) {
+		return hs, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取版本历史失败: %w", err)
+	}
+	if err := json.Unmarshal(data, &hs.packs); err != nil {
+		return nil, fmt.Errorf("解析版本历史失败: %w", err)
+	}
+	return hs, nil
+}
+
+func (hs *historyStore) enabled() bool {
+	return hs.limit > 0
+}
+
+func (hs *historyStore) indexPath() string {
+	return filepath.Join(hs.dir, "history.json")
+}
+
+func (hs *historyStore) blobDir() string {
+	return filepath.Join(hs.dir, "blobs")
+}
+
+func (hs *historyStore) blobPath(sha1 string) string {
+	return filepath.Join(hs.blobDir(), sha1+".zip")
+}
+
+// saveLocked 以先写临时文件再重命名的方式保存版本历史，调用方需持有 hs.mu
+func (hs *historyStore) saveLocked() error {
+	data, err := json.MarshalIndent(hs.packs, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return WriteFileAtomic(hs.indexPath(), data, 0644)
+}
+
+func (hs *historyStore) find(name, sha1 string) (VersionRecord, bool) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	if history, ok := hs.packs[name]; ok {
+		for _, version := range history.Versions {
+			if version.SHA1 == sha1 {
+				return version, true
+			}
+		}
+	}
+	return VersionRecord{}, false
+}
+
+// rename 将版本历史转移到资源包的新名称下
+func (hs *historyStore) rename(name, newName string, logger *zap.Logger) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	history, ok := hs.packs[name]
+	if !ok {
+		return
+	}
+	delete(hs.packs, name)
+	hs.packs[newName] = history
+	if err := hs.saveLocked(); err != nil {
+		logger.Error("保存版本历史失败", zap.Error(err))
+	}
+}
+
+func (hs *historyStore) findBlob(sha1 string) (string, VersionRecord, bool) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	for name, history := range hs.packs {
+		for _, version := range history.Versions {
+			if version.SHA1 == sha1 {
+				return name, version, true
+			}
+		}
+	}
+	return "", VersionRecord{}, false
+}
+
+// recordVersion 在资源包出现新版本时保存其 ZIP 并写入版本历史，超出数量的旧版本会被删除
+func (pm *PacksManager) recordVersion(rp *ResourcePack, uploader string) {
+	hs := pm.history
+	if !hs.enabled() {
+		return
+	}
+
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	history, ok := hs.packs[rp.Name]
+	if !ok {
+		history = &packHistory{}
+		hs.packs[rp.Name] = history
+	}
+	if len(history.Versions) > 0 && history.Versions[0].SHA1 == rp.SHA1 {
+		return
+	}
+
+	if err := pm.storeBlob(rp); err != nil {
+		pm.logger.Warn("保存资源包历史版本失败", zap.String("name", rp.Name), zap.String("sha1", rp.SHA1), zap.Error(err))
+		return
+	}
+
+	record := VersionRecord{
+		SHA1:        rp.SHA1,
+		SHA256:      rp.SHA256,
+		MD5:         rp.Hash,
+		Size:        rp.Size,
+		PackFormat:  rp.PackFormat,
+		Description: rp.Description,
+		Uploader:    uploader,
+		CreatedAt:   time.Now(),
+	}
+	versions := []VersionRecord{record}
+	for _, version := range history.Versions {
+		if version.SHA1 != rp.SHA1 {
+			versions = append(versions, version)
+		}
+	}
+
+	// 回滚中或被发布渠道引用的版本即使超出数量也保留
+	var kept []VersionRecord
+	for i, version := range versions {
+		if i < hs.limit || history.retained(version.SHA1) {
+			kept = append(kept, version)
+		}
+	}
+	history.Versions = kept
+
+	hs.removeUnreferencedBlobsLocked(pm.logger)
+	if err := hs.saveLocked(); err != nil {
+		pm.logger.Error("保存版本历史失败", zap.Error(err))
+	}
+	pm.logger.Info("已记录资源包版本", zap.String("name", rp.Name), zap.String("sha1", rp.SHA1))
+}
+
+// storeBlob 复制资源包当前的 ZIP 到状态目录，复制的同时校验内容，避免记录到写了一半的文件
+func (pm *PacksManager) storeBlob(rp *ResourcePack) error {
+	blobPath := pm.history.blobPath(rp.SHA1)
+	if fileExists(blobPath) {
+		return nil
+	}
+
+	sourcePath := rp.Path
+	if rp.IsDirectory {
+		sourcePath = pm.artifactPath(rp.SHA1)
+	}
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	return writeFileAtomicWith(blobPath, 0644, func(file *os.File) error {
+		digests := newDigestWriter()
+		if _, err := io.Copy(io.MultiWriter(file, digests), source); err != nil {
+			return err
+		}
+		if sum := digests.Digests(); sum.SHA1 != rp.SHA1 {
+			return fmt.Errorf("文件内容已变化: %s", sum.SHA1)
+		}
+		return nil
+	})
+}
+
+func (hs *historyStore) removeUnreferencedBlobsLocked(logger *zap.Logger) {
+	referenced := make(map[string]bool)
+	for _, history := range hs.packs {
+		for _, version := range history.Versions {
+			referenced[version.SHA1+".zip"] = true
+		}
+	}
+
+	entries, err := os.ReadDir(hs.blobDir())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || referenced[entry.Name()] || filepath.Ext(entry.Name()) != ".zip" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(hs.blobDir(), entry.Name())); err != nil {
+			logger.Warn("删除历史版本失败", zap.String("file", entry.Name()), zap.Error(err))
+		}
+	}
+}
+
+// applyHistory 记录新出现的版本，并将处于回滚状态的资源包替换为回滚的版本。
+// 调用方需持有 mutationMu，不应持有 pm.mu：保存版本时会复制整个 ZIP。
+// 只有与 baseline（资源包目录中原有的内容，为 nil 时取当前的资源包列表）不同的版本才会被记录，
+// 未变化的资源包不会被复制。资源包目录中的内容与回滚时不同，说明有了新版本，此时回滚失效。
+func (pm *PacksManager) applyHistory(changes, baseline map[string]*ResourcePack) {
+	hs := pm.history
+	if !hs.enabled() {
+		return
+	}
+
+	for name, rp := range changes {
+		if rp == nil {
+			pm.clearPin(name)
+			continue
+		}
+
+		previous := baseline[name]
+		if baseline == nil {
+			previous = pm.filesystemPack(name)
+		}
+		if previous == nil || previous.SHA1 != rp.SHA1 {
+			pm.recordVersion(rp, "")
+		}
+
+		hs.mu.Lock()
+		history := hs.packs[name]
+		pinned, pinnedFrom := "", ""
+		if history != nil {
+			pinned, pinnedFrom = history.Pinned, history.PinnedFrom
+		}
+		hs.mu.Unlock()
+
+		if pinned == "" {
+			continue
+		}
+		if rp.SHA1 != pinnedFrom {
+			pm.clearPin(name)
+			pm.logger.Info("资源包已有新版本，回滚失效", zap.String("name", name), zap.String("sha1", rp.SHA1))
+			continue
+		}
+
+		pinnedPack, err := pm.pinnedPack(name, pinned)
+		if err != nil {
+			pm.logger.Error("加载回滚版本失败", zap.String("name", name), zap.String("sha1", pinned), zap.Error(err))
+			pm.clearPin(name)
+			continue
+		}
+		hs.mu.Lock()
+		hs.sources[name] = rp
+		hs.mu.Unlock()
+		changes[name] = pinnedPack
+	}
+}
+
+func (pm *PacksManager) clearPin(name string) {
+	hs := pm.history
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	delete(hs.sources, name)
+	delete(hs.pinned, name)
+	history, ok := hs.packs[name]
+	if !ok || history.Pinned == "" {
+		return
+	}
+	history.Pinned, history.PinnedFrom = "", ""
+	if err := hs.saveLocked(); err != nil {
+		pm.logger.Error("保存版本历史失败", zap.Error(err))
+	}
+}
+
+func (pm *PacksManager) pinnedPack(name, sha1 string) (*ResourcePack, error) {
+	pm.history.mu.Lock()
+	cached := pm.history.pinned[name]
+	pm.history.mu.Unlock()
+	if cached != nil && cached.SHA1 == sha1 {
+		return cached, nil
+	}
+
+	rp, err := pm.loadZipPack(pm.history.blobPath(sha1))
+	if err != nil {
+		return nil, err
+	}
+	if rp.SHA1 != sha1 {
+		return nil, fmt.Errorf("历史版本文件已损坏")
+	}
+	rp.Name = name
+
+	pm.history.mu.Lock()
+	pm.history.pinned[name] = rp
+	pm.history.mu.Unlock()
+	return rp, nil
+}
+
+// historicPack 返回历史版本对应的资源包，直接使用状态目录中保存的 ZIP
+func (pm *PacksManager) historicPack(name string, record VersionRecord) (*ResourcePack, bool) {
+	stat, err := os.Stat(pm.history.blobPath(record.SHA1))
+	if err != nil {
+		return nil, false
+	}
+
+	return &ResourcePack{
+		Name:         name,
+		Path:         pm.history.blobPath(record.SHA1),
+		Description:  record.Description,
+		PackFormat:   record.PackFormat,
+		Size:         stat.Size(),
+		Hash:         record.MD5,
+		SHA1:         record.SHA1,
+		SHA256:       record.SHA256,
+		LastModified: stat.ModTime(),
+	}, true
+}
+
+// servedCatalog 将回滚中的资源包替换为当时对外提供的历史版本，仅用于比较变化、发布事件
+func (pm *PacksManager) servedCatalog(packs map[string]*ResourcePack) map[string]*ResourcePack {
+	hs := pm.history
+	if !hs.enabled() {
+		return packs
+	}
+
+	served := make(map[string]*ResourcePack, len(packs))
+	for name, rp := range packs {
+		served[name] = rp
+
+		hs.mu.Lock()
+		history := hs.packs[name]
+		pinned := ""
+		if history != nil && history.PinnedFrom == rp.SHA1 {
+			pinned = history.Pinned
+		}
+		hs.mu.Unlock()
+		if pinned == "" {
+			continue
+		}
+
+		if record, ok := hs.find(name, pinned); ok {
+			pinnedPack := *rp
+			pinnedPack.SHA1, pinnedPack.SHA256, pinnedPack.Hash, pinnedPack.Size = record.SHA1, record.SHA256, record.MD5, record.Size
+			served[name] = &pinnedPack
+		}
+	}
+	return served
+}
+
+// filesystemPack 返回资源包目录中的版本，回滚状态下 GetPack 返回的是历史版本
+func (pm *PacksManager) filesystemPack(name string) *ResourcePack {
+	pm.history.mu.Lock()
+	source := pm.history.sources[name]
+	pm.history.mu.Unlock()
+	if source != nil {
+		return source
+	}
+	return pm.GetPack(name)
+}
+
+func (pm *PacksManager) GetPackVersions(name string) (*PackVersions, error) {
+	current := pm.GetPack(name)
+
+	pm.history.mu.Lock()
+	defer pm.history.mu.Unlock()
+
+	history, ok := pm.history.packs[name]
+	if current == nil && !ok {
+		return nil, ErrPackNotFound
+	}
+
+	result := &PackVersions{Name: name, Versions: []VersionRecord{}}
+	if current != nil {
+		result.Current = current.SHA1
+	}
+	if ok {
+		result.RolledBack = history.Pinned != ""
+		result.Versions = append(result.Versions, history.Versions...)
+	}
+	return result, nil
+}
+
+// RollbackPack 将资源包对外提供的版本切换为历史版本，资源包目录中的文件不会被修改。
+// 回滚到资源包目录中的当前版本即取消回滚。
+func (pm *PacksManager) RollbackPack(name, sha1 string) (*ResourcePack, error) {
+	if !pm.history.enabled() {
+		return nil, ErrHistoryDisabled
+	}
+
+	pm.mutationMu.Lock()
+	defer pm.mutationMu.Unlock()
+
+	source := pm.filesystemPack(name)
+	if source == nil {
+		return nil, ErrPackNotFound
+	}
+	// 资源包目录中的当前版本不一定在版本历史中，回滚到它即取消回滚
+	if _, ok := pm.history.find(name, sha1); !ok && sha1 != source.SHA1 {
+		return nil, ErrPackNotFound
+	}
+
+	if sha1 == source.SHA1 {
+		pm.clearPin(name)
+	} else {
+		if !fileExists(pm.history.blobPath(sha1)) {
+			return nil, ErrVersionGone
+		}
+		pm.history.mu.Lock()
+		history := pm.history.packs[name]
+		history.Pinned, history.PinnedFrom = sha1, source.SHA1
+		err := pm.history.saveLocked()
+		pm.history.mu.Unlock()
+		if err != nil {
+			return nil, fmt.Errorf("保存版本历史失败: %w", err)
+		}
+	}
+
+	pm.updatePacks(map[string]*ResourcePack{name: source})
+
+	rp := pm.GetPack(name)
+	if rp == nil || rp.SHA1 != sha1 {
+		return nil, fmt.Errorf("回滚资源包 %s 失败", name)
+	}
+	pm.logger.Info("已回滚资源包", zap.String("name", name), zap.String("sha1", sha1))
+	return rp, nil
+}
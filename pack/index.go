@@ -0,0 +1,230 @@
+package pack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// indexVersion 在索引格式或 Hash 计算方式变化时递增，旧版本的索引会被丢弃
+const indexVersion = 1
+
+// indexEntry 记录资源包文件上次读取时的状态及读取结果
+type indexEntry struct {
+	Stamp string        `json:"stamp"`
+	Pack  *ResourcePack `json:"pack"`
+}
+
+type indexFile struct {
+	Version int                    `json:"version"`
+	Builder string                 `json:"builder"`
+	Entries map[string]*indexEntry `json:"entries"`
+}
+
+// packIndex 以资源包路径为键缓存读取结果，文件状态未变化时直接复用，不再重新计算 Hash。
+// path 不为空时持久化到该文件，重启后只需重新读取发生变化的资源包。
+type packIndex struct {
+	path    string
+	builder string
+
+	mu      sync.Mutex
+	entries map[string]*indexEntry
+	dirty   bool
+	loaded  bool
+}
+
+func newPackIndex(path, builder string) *packIndex {
+	return &packIndex{
+		path:    path,
+		builder: builder,
+		entries: make(map[string]*indexEntry),
+	}
+}
+
+// load 读取持久化的索引。目录资源包的 Hash 取决于打包实现、Go 版本与压缩级别，任何一项变化后整个索引作废；
+// Minecraft 版本范围按当前的格式对照表重新计算。
+func (idx *packIndex) load(formatTable *FormatTable) error {
+	if idx.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(idx.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("读取资源包索引失败: %w", err)
+	}
+
+	var file indexFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("解析资源包索引失败: %w", err)
+	}
+	if file.Version != indexVersion || file.Builder != idx.builder {
+		return nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for path, entry := range file.Entries {
+		if entry != nil && entry.Pack != nil {
+			entry.Pack.MinecraftVersions = formatTable.VersionRange(entry.Pack.SupportedFormats())
+			idx.entries[path] = entry
+		}
+	}
+	idx.loaded = true
+	return nil
+}
+
+// catalog 还原上次运行结束时资源包目录中的资源包列表，同名时 ZIP 文件优先于目录。
+// 没有成功读取持久化的索引时返回 nil。
+func (idx *packIndex) catalog() map[string]*ResourcePack {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if !idx.loaded {
+		return nil
+	}
+	packs := make(map[string]*ResourcePack, len(idx.entries))
+	for _, entry := range idx.entries {
+		if existing, ok := packs[entry.Pack.Name]; ok && !existing.IsDirectory {
+			continue
+		}
+		packs[entry.Pack.Name] = entry.Pack
+	}
+	return packs
+}
+
+// save 在索引变化后写入文件
+func (idx *packIndex) save() error {
+	if idx.path == "" {
+		return nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if !idx.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(indexFile{
+		Version: indexVersion,
+		Builder: idx.builder,
+		Entries: idx.entries,
+	})
+	if err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(idx.path, data, 0644); err != nil {
+		return err
+	}
+	idx.dirty = false
+	return nil
+}
+
+func (idx *packIndex) lookup(path, stamp string) *ResourcePack {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if entry, ok := idx.entries[path]; ok && entry.Stamp == stamp {
+		return entry.Pack
+	}
+	return nil
+}
+
+func (idx *packIndex) store(path, stamp string, rp *ResourcePack) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.entries[path] = &indexEntry{Stamp: stamp, Pack: rp}
+	idx.dirty = true
+}
+
+func (idx *packIndex) forget(paths ...string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for _, path := range paths {
+		if _, ok := idx.entries[path]; ok {
+			delete(idx.entries, path)
+			idx.dirty = true
+		}
+	}
+}
+
+// retain 删除不在 paths 中的记录，完整扫描结束后调用
+func (idx *packIndex) retain(paths map[string]bool) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for path := range idx.entries {
+		if !paths[path] {
+			delete(idx.entries, path)
+			idx.dirty = true
+		}
+	}
+}
+
+// packStamp 描述资源包文件的当前状态：ZIP 文件为大小、修改时间与 inode，目录为目录指纹
+func (pm *PacksManager) packStamp(path string, isDir bool) (string, error) {
+	if isDir {
+		fingerprint, err := pm.calculateDirectoryFingerprint(path)
+		if err != nil {
+			return "", err
+		}
+		return "dir:" + fingerprint, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("zip:%d:%d:%d", info.Size(), info.ModTime().UnixNano(), fileInode(info)), nil
+}
+
+// loadPack 读取资源包，文件状态与上次读取时相同则复用上次的结果
+func (pm *PacksManager) loadPack(path string, isDir bool) (*ResourcePack, error) {
+	stamp, err := pm.packStamp(path, isDir)
+	if err != nil {
+		return nil, err
+	}
+	if rp := pm.index.lookup(path, stamp); rp != nil {
+		return rp, nil
+	}
+
+	var rp *ResourcePack
+	if isDir {
+		rp, err = pm.loadDirectoryPack(path)
+	} else {
+		rp, err = pm.loadZipPack(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	pm.index.store(path, stamp, rp)
+	return rp, nil
+}
+
+func (pm *PacksManager) saveIndex() {
+	if err := pm.index.save(); err != nil {
+		pm.logger.Warn("保存资源包索引失败", zap.Error(err))
+	}
+}
+
+// warmDirectoryArtifacts 为从索引恢复的目录资源包在后台生成 ZIP，避免首次下载时才打包
+func (pm *PacksManager) warmDirectoryArtifacts() {
+	for _, rp := range pm.GetAllPacks() {
+		if !rp.IsDirectory {
+			continue
+		}
+		pm.zipCacheMutex.RLock()
+		artifact := pm.zipCache[rp.SHA1]
+		pm.zipCacheMutex.RUnlock()
+		if artifact != nil && fileExists(artifact.path) {
+			continue
+		}
+		if _, err := pm.ArtifactPath(rp); err != nil {
+			pm.logger.Warn("生成目录资源包 ZIP 失败", zap.String("name", rp.Name), zap.Error(err))
+		}
+	}
+}
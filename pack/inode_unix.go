@@ -0,0 +1,16 @@
+//go:build !windows
+
+package pack
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileInode 返回文件的 inode，用于识别被原地替换（rename 覆盖）的文件
+func fileInode(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino)
+	}
+	return 0
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package pack
+
+import "os"
+
+// fileInode 在 Windows 上不可用，只依赖大小与修改时间判断变化
+func fileInode(info os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,135 @@
+package pack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DeletePack 删除资源包文件或目录。目录会先被重命名为隐藏目录再删除，
+// 避免扫描到删除了一半的资源包。
+func (pm *PacksManager) DeletePack(name string) (*ResourcePack, error) {
+	pm.mutationMu.Lock()
+	defer pm.mutationMu.Unlock()
+
+	rp := pm.filesystemPack(name)
+	if rp == nil {
+		return nil, ErrPackNotFound
+	}
+	if err := pm.checkMutable(rp); err != nil {
+		return nil, err
+	}
+	pm.index.forget(rp.Path)
+
+	if rp.IsDirectory {
+		trashPath := filepath.Join(pm.packsDirectory, fmt.Sprintf(".deleting-%s-%d", name, time.Now().UnixNano()))
+		if err := os.Rename(rp.Path, trashPath); err != nil {
+			return nil, fmt.Errorf("移动资源包目录失败: %w", err)
+		}
+		pm.updatePacks(map[string]*ResourcePack{name: nil})
+		if err := os.RemoveAll(trashPath); err != nil {
+			pm.logger.Warn("删除资源包目录失败", zap.String("path", trashPath), zap.Error(err))
+		}
+	} else {
+		if err := os.Remove(rp.Path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("删除资源包文件失败: %w", err)
+		}
+		pm.updatePacks(map[string]*ResourcePack{name: nil})
+	}
+
+	pm.logger.Info("已删除资源包", zap.String("name", name), zap.String("sha1", rp.SHA1))
+	return rp, nil
+}
+
+func (pm *PacksManager) RenamePack(name, newName string) (*ResourcePack, error) {
+	if err := ValidatePackName(newName); err != nil {
+		return nil, err
+	}
+
+	pm.mutationMu.Lock()
+	defer pm.mutationMu.Unlock()
+
+	rp := pm.filesystemPack(name)
+	if rp == nil {
+		return nil, ErrPackNotFound
+	}
+	if err := pm.checkMutable(rp); err != nil {
+		return nil, err
+	}
+	if newName == name {
+		return rp, nil
+	}
+	if pm.GetPack(newName) != nil {
+		return nil, fmt.Errorf("%w: 资源包 %s 已存在", ErrPackConflict, newName)
+	}
+
+	newPath := filepath.Join(pm.packsDirectory, newName)
+	if !rp.IsDirectory {
+		newPath += ".zip"
+	}
+	if _, err := os.Lstat(newPath); err == nil {
+		return nil, fmt.Errorf("%w: %s 已存在", ErrPackConflict, filepath.Base(newPath))
+	}
+
+	if err := os.Rename(rp.Path, newPath); err != nil {
+		return nil, fmt.Errorf("重命名资源包失败: %w", err)
+	}
+
+	pm.index.forget(rp.Path)
+	renamed, err := pm.loadPack(newPath, rp.IsDirectory)
+	if err != nil {
+		pm.updatePacks(map[string]*ResourcePack{name: nil})
+		return nil, fmt.Errorf("加载重命名后的资源包失败: %w", err)
+	}
+
+	pm.history.rename(name, newName, pm.logger)
+	pm.updatePacks(map[string]*ResourcePack{name: nil, newName: renamed})
+	pm.logger.Info("已重命名资源包", zap.String("name", name), zap.String("new_name", newName))
+	return pm.GetPack(newName), nil
+}
+
+// checkMutable 资源包目录本身作为资源包时不允许通过 API 删除或重命名
+func (pm *PacksManager) checkMutable(rp *ResourcePack) error {
+	if filepath.Clean(rp.Path) == filepath.Clean(pm.packsDirectory) {
+		return fmt.Errorf("%w: 无法修改资源包根目录", ErrPackConflict)
+	}
+	return nil
+}
+
+// updatePacks 原子地替换一组资源包（值为 nil 表示移除），并像完整扫描一样记录旧版本、清理缓存。
+// 调用方需持有 mutationMu；保存历史版本可能需要复制整个 ZIP，因此在获取 pm.mu 之前完成。
+func (pm *PacksManager) updatePacks(changes map[string]*ResourcePack) {
+	defer pm.saveIndex()
+
+	pm.applyHistory(changes, nil)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	previous := make(map[string]*ResourcePack)
+	updated := make(map[string]*ResourcePack)
+	packs := make(map[string]*ResourcePack, len(pm.packs)+len(changes))
+	for packName, pack := range pm.packs {
+		packs[packName] = pack
+	}
+	for name, rp := range changes {
+		if old, ok := pm.packs[name]; ok {
+			previous[name] = old
+		}
+		if rp != nil {
+			packs[name] = rp
+			updated[name] = rp
+		} else {
+			delete(packs, name)
+		}
+	}
+	pm.packs = packs
+
+	pm.publishChanges(previous, updated)
+
+	pm.recordSuperseded(previous)
+	pm.evictStaleArtifacts()
+}
@@ -0,0 +1,417 @@
+package pack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PackMcmeta 对应资源包根目录下的 pack.mcmeta
+type PackMcmeta struct {
+	Pack     PackSection              `json:"pack"`
+	Overlays *OverlaysSection         `json:"overlays,omitempty"`
+	Filter   *FilterSection           `json:"filter,omitempty"`
+	Language map[string]LanguageEntry `json:"language,omitempty"`
+}
+
+type PackSection struct {
+	PackFormat       int           `json:"pack_format,omitempty"`
+	Description      TextComponent `json:"description"`
+	SupportedFormats *FormatRange  `json:"supported_formats,omitempty"`
+	MinFormat        *PackVersion  `json:"min_format,omitempty"`
+	MaxFormat        *PackVersion  `json:"max_format,omitempty"`
+}
+
+type OverlaysSection struct {
+	Entries []OverlayEntry `json:"entries"`
+}
+
+type OverlayEntry struct {
+	Directory string       `json:"directory"`
+	Formats   *FormatRange `json:"formats,omitempty"`
+	MinFormat *PackVersion `json:"min_format,omitempty"`
+	MaxFormat *PackVersion `json:"max_format,omitempty"`
+}
+
+type FilterSection struct {
+	Block []FilterPattern `json:"block"`
+}
+
+type FilterPattern struct {
+	Namespace string `json:"namespace,omitempty"`
+	Path      string `json:"path,omitempty"`
+}
+
+type LanguageEntry struct {
+	Name          string `json:"name"`
+	Region        string `json:"region"`
+	Bidirectional bool   `json:"bidirectional,omitempty"`
+}
+
+func ParsePackMcmeta(data []byte) (*PackMcmeta, error) {
+	// 部分编辑器会写入 UTF-8 BOM
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var mcmeta PackMcmeta
+	if err := json.Unmarshal(data, &mcmeta); err != nil {
+		return nil, fmt.Errorf("解析 pack.mcmeta 失败: %w", err)
+	}
+	if mcmeta.Pack.PackFormat == 0 && mcmeta.Pack.MinFormat == nil {
+		return nil, fmt.Errorf("pack.mcmeta 缺少 pack_format")
+	}
+	return &mcmeta, nil
+}
+
+// Format 返回资源包声明的格式版本，新版本的 pack.mcmeta 可能只提供 min_format
+func (m *PackMcmeta) Format() int {
+	if m.Pack.PackFormat != 0 {
+		return m.Pack.PackFormat
+	}
+	if m.Pack.MinFormat != nil {
+		return m.Pack.MinFormat.Major
+	}
+	return 0
+}
+
+// SupportedFormats 返回资源包本身及各 overlay 声明支持的格式范围
+func (m *PackMcmeta) SupportedFormats() []FormatRange {
+	var ranges []FormatRange
+	switch {
+	case m.Pack.SupportedFormats != nil:
+		ranges = append(ranges, *m.Pack.SupportedFormats)
+	case m.Pack.MinFormat != nil && m.Pack.MaxFormat != nil:
+		ranges = append(ranges, FormatRange{Min: m.Pack.MinFormat.Major, Max: m.Pack.MaxFormat.Major})
+	default:
+		ranges = append(ranges, FormatRange{Min: m.Format(), Max: m.Format()})
+	}
+
+	if m.Overlays != nil {
+		for _, overlay := range m.Overlays.Entries {
+			switch {
+			case overlay.Formats != nil:
+				ranges = append(ranges, *overlay.Formats)
+			case overlay.MinFormat != nil && overlay.MaxFormat != nil:
+				ranges = append(ranges, FormatRange{Min: overlay.MinFormat.Major, Max: overlay.MaxFormat.Major})
+			}
+		}
+	}
+	return ranges
+}
+
+// FormatRange 对应 supported_formats / formats，可以是整数、[min, max] 或
+// {"min_inclusive": min, "max_inclusive": max}
+type FormatRange struct {
+	Min int `json:"min_inclusive"`
+	Max int `json:"max_inclusive"`
+}
+
+func (r *FormatRange) UnmarshalJSON(data []byte) error {
+	var single int
+	if err := json.Unmarshal(data, &single); err == nil {
+		r.Min, r.Max = single, single
+		return nil
+	}
+
+	var pair []int
+	if err := json.Unmarshal(data, &pair); err == nil {
+		if len(pair) != 2 {
+			return fmt.Errorf("格式范围数组必须包含两个元素")
+		}
+		r.Min, r.Max = pair[0], pair[1]
+		return nil
+	}
+
+	var object struct {
+		Min *int `json:"min_inclusive"`
+		Max *int `json:"max_inclusive"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("无效的格式范围: %s", data)
+	}
+	if object.Min == nil || object.Max == nil {
+		return fmt.Errorf("格式范围缺少 min_inclusive 或 max_inclusive")
+	}
+	r.Min, r.Max = *object.Min, *object.Max
+	return nil
+}
+
+func (r FormatRange) Contains(format int) bool {
+	return format >= r.Min && format <= r.Max
+}
+
+// PackVersion 对应 min_format / max_format，可以是整数、[major] 或 [major, minor]
+type PackVersion struct {
+	Major int
+	Minor int
+}
+
+func (v *PackVersion) UnmarshalJSON(data []byte) error {
+	var single int
+	if err := json.Unmarshal(data, &single); err == nil {
+		v.Major, v.Minor = single, 0
+		return nil
+	}
+
+	var parts []int
+	if err := json.Unmarshal(data, &parts); err != nil || len(parts) == 0 || len(parts) > 2 {
+		return fmt.Errorf("无效的格式版本: %s", data)
+	}
+	v.Major = parts[0]
+	if len(parts) == 2 {
+		v.Minor = parts[1]
+	}
+	return nil
+}
+
+func (v PackVersion) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]int{v.Major, v.Minor})
+}
+
+func (v PackVersion) String() string {
+	if v.Minor == 0 {
+		return strconv.Itoa(v.Major)
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// TextComponent 保留 description 的原始 JSON，可以是字符串、文本组件对象或数组
+type TextComponent struct {
+	raw json.RawMessage
+}
+
+func (t *TextComponent) UnmarshalJSON(data []byte) error {
+	t.raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+func (t TextComponent) MarshalJSON() ([]byte, error) {
+	if len(t.raw) == 0 {
+		return []byte(`""`), nil
+	}
+	return t.raw, nil
+}
+
+func (t TextComponent) PlainText() string {
+	var builder strings.Builder
+	for _, segment := range t.segments() {
+		builder.WriteString(segment.text)
+	}
+	return builder.String()
+}
+
+func (t TextComponent) HTML() string {
+	var builder strings.Builder
+	for _, segment := range t.segments() {
+		text := strings.ReplaceAll(html.EscapeString(segment.text), "\n", "<br>")
+		css := segment.style.css()
+		if css == "" {
+			builder.WriteString(text)
+			continue
+		}
+		fmt.Fprintf(&builder, `<span style="%s">%s</span>`, html.EscapeString(css), text)
+	}
+	return builder.String()
+}
+
+func (t TextComponent) segments() []textSegment {
+	if len(t.raw) == 0 {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(t.raw, &value); err != nil {
+		return nil
+	}
+
+	var segments []textSegment
+	appendComponent(&segments, value, textStyle{})
+	return segments
+}
+
+type textSegment struct {
+	text  string
+	style textStyle
+}
+
+type textStyle struct {
+	color         string
+	bold          bool
+	italic        bool
+	underlined    bool
+	strikethrough bool
+	obfuscated    bool
+}
+
+func (s textStyle) css() string {
+	var rules []string
+	if s.color != "" {
+		rules = append(rules, "color: "+s.color)
+	}
+	if s.bold {
+		rules = append(rules, "font-weight: bold")
+	}
+	if s.italic {
+		rules = append(rules, "font-style: italic")
+	}
+	var decorations []string
+	if s.underlined {
+		decorations = append(decorations, "underline")
+	}
+	if s.strikethrough {
+		decorations = append(decorations, "line-through")
+	}
+	if len(decorations) > 0 {
+		rules = append(rules, "text-decoration: "+strings.Join(decorations, " "))
+	}
+	return strings.Join(rules, "; ")
+}
+
+var namedColors = map[string]string{
+	"black":        "#000000",
+	"dark_blue":    "#0000AA",
+	"dark_green":   "#00AA00",
+	"dark_aqua":    "#00AAAA",
+	"dark_red":     "#AA0000",
+	"dark_purple":  "#AA00AA",
+	"gold":         "#FFAA00",
+	"gray":         "#AAAAAA",
+	"dark_gray":    "#555555",
+	"blue":         "#5555FF",
+	"green":        "#55FF55",
+	"aqua":         "#55FFFF",
+	"red":          "#FF5555",
+	"light_purple": "#FF55FF",
+	"yellow":       "#FFFF55",
+	"white":        "#FFFFFF",
+}
+
+// legacyColors 按 § 格式代码 0-f 的顺序排列
+var legacyColors = []string{
+	"#000000", "#0000AA", "#00AA00", "#00AAAA", "#AA0000", "#AA00AA", "#FFAA00", "#AAAAAA",
+	"#555555", "#5555FF", "#55FF55", "#55FFFF", "#FF5555", "#FF55FF", "#FFFF55", "#FFFFFF",
+}
+
+func appendComponent(segments *[]textSegment, value interface{}, style textStyle) {
+	switch v := value.(type) {
+	case string:
+		appendLegacyText(segments, v, style)
+	case float64:
+		appendLegacyText(segments, strconv.FormatFloat(v, 'f', -1, 64), style)
+	case bool:
+		appendLegacyText(segments, strconv.FormatBool(v), style)
+	case []interface{}:
+		// 数组中后续元素继承第一个元素的样式
+		if len(v) == 0 {
+			return
+		}
+		appendComponent(segments, v[0], style)
+		if first, ok := v[0].(map[string]interface{}); ok {
+			style = style.merge(first)
+		}
+		for _, child := range v[1:] {
+			appendComponent(segments, child, style)
+		}
+	case map[string]interface{}:
+		style = style.merge(v)
+		appendLegacyText(segments, componentText(v), style)
+		if extra, ok := v["extra"].([]interface{}); ok {
+			for _, child := range extra {
+				appendComponent(segments, child, style)
+			}
+		}
+	}
+}
+
+func componentText(component map[string]interface{}) string {
+	if text, ok := component["text"]; ok {
+		return fmt.Sprint(text)
+	}
+	if fallback, ok := component["fallback"].(string); ok {
+		return fallback
+	}
+	if translate, ok := component["translate"].(string); ok {
+		return translate
+	}
+	if keybind, ok := component["keybind"].(string); ok {
+		return keybind
+	}
+	if selector, ok := component["selector"].(string); ok {
+		return selector
+	}
+	return ""
+}
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+func (s textStyle) merge(component map[string]interface{}) textStyle {
+	if color, ok := component["color"].(string); ok {
+		if hex, ok := namedColors[color]; ok {
+			s.color = hex
+		} else if hexColorPattern.MatchString(color) {
+			s.color = color
+		}
+	}
+	if v, ok := component["bold"].(bool); ok {
+		s.bold = v
+	}
+	if v, ok := component["italic"].(bool); ok {
+		s.italic = v
+	}
+	if v, ok := component["underlined"].(bool); ok {
+		s.underlined = v
+	}
+	if v, ok := component["strikethrough"].(bool); ok {
+		s.strikethrough = v
+	}
+	if v, ok := component["obfuscated"].(bool); ok {
+		s.obfuscated = v
+	}
+	return s
+}
+
+// appendLegacyText 处理字符串中的 § 格式代码
+func appendLegacyText(segments *[]textSegment, text string, style textStyle) {
+	current := style
+	var builder strings.Builder
+	flush := func() {
+		if builder.Len() > 0 {
+			*segments = append(*segments, textSegment{text: builder.String(), style: current})
+			builder.Reset()
+		}
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '§' || i+1 >= len(runes) {
+			builder.WriteRune(runes[i])
+			continue
+		}
+
+		flush()
+		i++
+		code := strings.ToLower(string(runes[i]))
+		if index := strings.Index("0123456789abcdef", code); index >= 0 {
+			current = textStyle{color: legacyColors[index]}
+			continue
+		}
+		switch code {
+		case "k":
+			current.obfuscated = true
+		case "l":
+			current.bold = true
+		case "m":
+			current.strikethrough = true
+		case "n":
+			current.underlined = true
+		case "o":
+			current.italic = true
+		case "r":
+			current = style
+		}
+	}
+	flush()
+}
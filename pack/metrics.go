@@ -0,0 +1,16 @@
+package pack
+
+import "resourcepack-server/metrics"
+
+var (
+	scansTotal = metrics.NewCounter("resourcepack_scans_total",
+		"资源包目录扫描次数")
+	scanDuration = metrics.NewHistogram("resourcepack_scan_duration_seconds",
+		"资源包目录扫描耗时", metrics.DurationBuckets)
+	zipBuildDuration = metrics.NewHistogram("resourcepack_zip_build_duration_seconds",
+		"目录资源包打包为 ZIP 的耗时", metrics.DurationBuckets)
+	zipCacheRequests = metrics.NewCounter("resourcepack_zip_cache_requests_total",
+		"目录资源包 ZIP 缓存的命中情况", "result")
+	watcherEvents = metrics.NewCounter("resourcepack_file_watcher_events_total",
+		"文件监控收到的事件数", "op")
+)
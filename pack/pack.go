@@ -3,9 +3,10 @@ package pack
 import (
 	"archive/zip"
 	"crypto/md5"
-	"encoding/json"
 	"fmt"
+	"html"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -18,33 +19,65 @@ import (
 )
 
 type ResourcePack struct {
-	Name         string    `json:"name"`
-	Path         string    `json:"path"`
-	Description  string    `json:"description"`
-	PackFormat   int       `json:"pack_format"`
-	Size         int64     `json:"size"`
-	Hash         string    `json:"hash"`
-	LastModified time.Time `json:"last_modified"`
-	IsDirectory  bool      `json:"is_directory"`
+	Name         string      `json:"name"`
+	Path         string      `json:"path"`
+	Description  string      `json:"description"`
+	PackFormat   int         `json:"pack_format"`
+	Size         int64       `json:"size"`
+	Hash         string      `json:"hash"`
+	SHA1         string      `json:"sha1"`
+	SHA256       string      `json:"sha256"`
+	LastModified time.Time   `json:"last_modified"`
+	IsDirectory  bool        `json:"is_directory"`
+	Mcmeta       *PackMcmeta `json:"mcmeta,omitempty"`
+	// MinecraftVersions 由格式对照表推算，包括 overlay 支持的版本
+	MinecraftVersions *VersionRange `json:"minecraft_versions,omitempty"`
 }
 
 func (rp *ResourcePack) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"name":          rp.Name,
-		"description":   rp.Description,
-		"pack_format":   rp.PackFormat,
-		"size":          rp.Size,
-		"hash":          rp.Hash,
-		"last_modified": rp.LastModified.Unix(),
-		"is_directory":  rp.IsDirectory,
-		"download_url":  fmt.Sprintf("/download/%s", rp.Name),
-		"hash_url":      fmt.Sprintf("/hash/%s", rp.Name),
+		"name":               rp.Name,
+		"description":        rp.Description,
+		"pack_format":        rp.PackFormat,
+		"size":               rp.Size,
+		"hash":               rp.Hash,
+		"sha1":               rp.SHA1,
+		"sha256":             rp.SHA256,
+		"last_modified":      rp.LastModified.Unix(),
+		"is_directory":       rp.IsDirectory,
+		"supported_formats":  rp.SupportedFormats(),
+		"minecraft_versions": rp.MinecraftVersions,
+		"download_url":       fmt.Sprintf("/download/%s/%s", url.PathEscape(rp.Name), rp.SHA1),
+		"latest_url":         fmt.Sprintf("/download/%s", url.PathEscape(rp.Name)),
+		"hash_url":           fmt.Sprintf("/hash/%s", url.PathEscape(rp.Name)),
 	}
 }
 
-type PackInfo struct {
-	Description string `json:"description"`
-	PackFormat  int    `json:"pack_format"`
+func (rp *ResourcePack) Digests() Digests {
+	return Digests{
+		MD5:    rp.Hash,
+		SHA1:   rp.SHA1,
+		SHA256: rp.SHA256,
+	}
+}
+
+func (rp *ResourcePack) SupportedFormats() []FormatRange {
+	if rp.Mcmeta == nil {
+		return []FormatRange{}
+	}
+	return rp.Mcmeta.SupportedFormats()
+}
+
+// SupportsFormat 判断资源包（含 overlay）是否声明支持指定格式
+func (rp *ResourcePack) SupportsFormat(format int) bool {
+	return formatInRanges(format, rp.SupportedFormats())
+}
+
+func (rp *ResourcePack) DescriptionHTML() string {
+	if rp.Mcmeta != nil {
+		return rp.Mcmeta.Pack.Description.HTML()
+	}
+	return html.EscapeString(rp.Description)
 }
 
 type PacksManager struct {
@@ -53,34 +86,110 @@ type PacksManager struct {
 	packsDirectory  string
 	tempDir         string
 	packs           map[string]*ResourcePack
-	zipCache        map[string]string
+	superseded      map[string]*supersededPack
+	history         *historyStore
+	events          *eventBroker
+	validations     map[string]*ValidationReport
+	validationsMu   sync.Mutex
+	previews        *previewCache
+	zipBuilder      *ZipBuilder
+	formatTable     *FormatTable
+	zipCache        map[string]*zipArtifact
+	zipSources      map[string]zipSource
 	zipCacheMutex   sync.RWMutex
+	zipBuilds       map[string]*zipBuild
+	zipBuildsMu     sync.Mutex
 	mu              sync.RWMutex
+	mutationMu      sync.Mutex
 	fileWatcher     *fsnotify.Watcher
 	fileMonitorStop chan struct{}
-	lastScanTime    time.Time
-	scanCooldown    time.Duration
+	index           *packIndex
+	// debouncer 合并文件变化与下载时发现的过期资源包触发的重新加载，未启用文件监控时同样使用
+	debouncer *debouncer
+	// startupBaseline 为从索引还原的上次运行时的资源包列表，启动扫描以它为基准发布变化事件
+	startupBaseline map[string]*ResourcePack
+	startupEventID  uint64
+	// scanned 在第一次完整扫描完成后为 true，由 mutationMu 保护
+	scanned bool
 }
 
 type Config struct {
-	Directory           string
-	FileMonitor         bool
+	Directory   string
+	FileMonitor bool
+	// FileMonitorMode 为 fsnotify（默认）或 poll，poll 每隔 FileMonitorInterval 遍历一次目录
+	FileMonitorMode     string
 	FileMonitorInterval time.Duration
 	ScanCooldown        time.Duration
+	// ScanWorkers 扫描时同时读取资源包的数量，0 表示 CPU 核心数
+	ScanWorkers      int
+	CompressionLevel int
+	// FormatTableFile 可选的格式对照表 JSON 文件，用于补充内置表
+	FormatTableFile string
+	// MaxUploadSize 通过 API 上传的资源包大小上限（字节），0 表示不限制
+	MaxUploadSize int64
+	// SupersededGracePeriod 旧版本被替换后仍可通过 Hash 下载的时间
+	SupersededGracePeriod time.Duration
+	// StateDir 保存版本历史等持久化数据的目录
+	StateDir string
+	// HistorySize 每个资源包保留的历史版本数，0 表示不保留
+	HistorySize int
+	// RebuildIndex 启动时忽略 <StateDir>/index.json，重新读取所有资源包
+	RebuildIndex bool
 }
 
 func NewPacksManager(config *Config, logger *zap.Logger) (*PacksManager, error) {
+	zipBuilder, err := NewZipBuilder(config.CompressionLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.FileMonitorMode {
+	case "", FileMonitorModeFsnotify, FileMonitorModePoll:
+	default:
+		return nil, fmt.Errorf("不支持的文件监控模式: %s", config.FileMonitorMode)
+	}
+
+	formatTable := DefaultFormatTable()
+	if config.FormatTableFile != "" {
+		if formatTable, err = LoadFormatTable(config.FormatTableFile); err != nil {
+			return nil, err
+		}
+	}
+
+	history, err := newHistoryStore(config.StateDir, config.HistorySize)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexPath string
+	if config.StateDir != "" {
+		if err := os.MkdirAll(config.StateDir, 0755); err != nil {
+			return nil, fmt.Errorf("创建状态目录失败: %w", err)
+		}
+		indexPath = filepath.Join(config.StateDir, "index.json")
+	}
+
 	pm := &PacksManager{
 		config:          config,
 		logger:          logger,
 		packsDirectory:  config.Directory,
 		tempDir:         os.TempDir() + "/resourcepack_server",
 		packs:           make(map[string]*ResourcePack),
-		zipCache:        make(map[string]string),
+		superseded:      make(map[string]*supersededPack),
+		history:         history,
+		index:           newPackIndex(indexPath, zipBuilder.buildID()),
+		events:          newEventBroker(),
+		validations:     make(map[string]*ValidationReport),
+		previews:        newPreviewCache(),
+		zipBuilder:      zipBuilder,
+		formatTable:     formatTable,
+		zipCache:        make(map[string]*zipArtifact),
+		zipSources:      make(map[string]zipSource),
 		zipCacheMutex:   sync.RWMutex{},
+		zipBuilds:       make(map[string]*zipBuild),
 		fileMonitorStop: make(chan struct{}),
-		scanCooldown:    config.ScanCooldown,
 	}
+	pm.debouncer = newDebouncer(config.FileMonitorInterval, config.ScanCooldown, pm.reloadAfterChange)
 
 	if err := os.MkdirAll(pm.tempDir, 0755); err != nil {
 		return nil, fmt.Errorf("创建临时目录失败: %w", err)
@@ -90,9 +199,23 @@ func NewPacksManager(config *Config, logger *zap.Logger) (*PacksManager, error)
 		return nil, fmt.Errorf("创建资源包目录失败: %w", err)
 	}
 
+	if config.RebuildIndex {
+		logger.Info("忽略资源包索引，重新读取所有资源包")
+	} else if err := pm.index.load(formatTable); err != nil {
+		logger.Warn("资源包索引无效，将重新读取所有资源包", zap.Error(err))
+	}
+
+	pm.startupBaseline = pm.index.catalog()
+	pm.startupEventID = pm.events.lastID()
 	if err := pm.scanPacks(); err != nil {
 		logger.Error("初始扫描资源包失败", zap.Error(err))
 	}
+	if pm.startupBaseline != nil {
+		pm.startupBaseline = nil
+	} else {
+		pm.startupEventID = pm.events.lastID()
+	}
+	go pm.warmDirectoryArtifacts()
 
 	if config.FileMonitor {
 		if err := pm.startFileMonitoring(); err != nil {
@@ -103,64 +226,46 @@ func NewPacksManager(config *Config, logger *zap.Logger) (*PacksManager, error)
 	return pm, nil
 }
 
+// scanPacks 在不持有 pm.mu 的情况下并发读取所有资源包，完成后一次性替换资源包列表，
+// 扫描期间的请求仍使用旧的列表。
 func (pm *PacksManager) scanPacks() error {
-	pm.mu.Lock()
-	defer pm.mu.Unlock()
+	pm.mutationMu.Lock()
+	defer pm.mutationMu.Unlock()
 
-	oldPacks := make(map[string]bool)
-	for name := range pm.packs {
-		oldPacks[name] = true
-	}
-
-	pm.packs = make(map[string]*ResourcePack)
+	startTime := time.Now()
 	pm.logger.Info("开始扫描资源包目录", zap.String("directory", pm.packsDirectory))
 
-	if pm.isResourcePackDirectory(pm.packsDirectory) {
-		pack, err := pm.loadDirectoryPack(pm.packsDirectory)
-		if err == nil && pack != nil {
-			pm.packs[pack.Name] = pack
-			pm.logger.Info("发现根目录资源包", zap.String("name", pack.Name))
-		}
-	}
-
-	entries, err := os.ReadDir(pm.packsDirectory)
+	candidates, err := pm.findPackCandidates()
 	if err != nil {
-		return fmt.Errorf("读取目录失败: %w", err)
+		return err
 	}
+	results := pm.loadCandidates(candidates)
 
-	for _, entry := range entries {
-		entryPath := filepath.Join(pm.packsDirectory, entry.Name())
-
-		if entry.IsDir() {
-			if pm.isResourcePackDirectory(entryPath) {
-				pack, err := pm.loadDirectoryPack(entryPath)
-				if err == nil && pack != nil {
-					pm.packs[pack.Name] = pack
-					pm.logger.Info("发现子目录资源包", zap.String("name", pack.Name))
-				}
-			}
-		} else if strings.HasSuffix(entry.Name(), ".zip") {
-			pack, err := pm.loadZipPack(entryPath)
-			if err == nil && pack != nil {
-				pm.packs[pack.Name] = pack
-				pm.logger.Info("发现ZIP资源包", zap.String("name", pack.Name))
-			}
+	// 同名时后出现的覆盖先出现的，因此 ZIP 文件优先于同名目录
+	packs := make(map[string]*ResourcePack)
+	paths := make(map[string]bool)
+	for i, candidate := range candidates {
+		paths[candidate.path] = true
+		if results[i] != nil {
+			packs[candidate.name] = results[i]
 		}
 	}
+	pm.index.retain(paths)
+	pm.saveIndex()
 
-	newPacks := make(map[string]bool)
-	for name := range pm.packs {
-		newPacks[name] = true
-	}
+	// 资源包列表只会在持有 mutationMu 时被替换，此处读取到的就是本次扫描前的列表
+	pm.mu.RLock()
+	previousPacks := pm.packs
+	pm.mu.RUnlock()
 
 	var added, removed []string
-	for name := range newPacks {
-		if !oldPacks[name] {
+	for name := range packs {
+		if _, ok := previousPacks[name]; !ok {
 			added = append(added, name)
 		}
 	}
-	for name := range oldPacks {
-		if !newPacks[name] {
+	for name := range previousPacks {
+		if _, ok := packs[name]; !ok {
 			removed = append(removed, name)
 		}
 	}
@@ -170,11 +275,41 @@ func (pm *PacksManager) scanPacks() error {
 	}
 	if len(removed) > 0 {
 		pm.logger.Info("移除资源包", zap.Strings("names", removed))
-		pm.cleanupZipCache(removed)
+	}
+	for _, name := range removed {
+		pm.clearPin(name)
+	}
+	// 没有索引的首次扫描中，资源包目录里已有的内容不算新版本，不复制到版本历史中
+	historyBase := pm.startupBaseline
+	if historyBase == nil && !pm.scanned {
+		historyBase = packs
+	}
+	pm.applyHistory(packs, historyBase)
+
+	// 启动扫描与上次运行时对外提供的资源包比较，停机期间的变化同样会发布事件
+	eventBase := previousPacks
+	if pm.startupBaseline != nil {
+		eventBase = pm.servedCatalog(pm.startupBaseline)
 	}
 
-	pm.logger.Info("扫描完成", zap.Int("count", len(pm.packs)))
-	pm.lastScanTime = time.Now()
+	pm.mu.Lock()
+	pm.packs = packs
+	pm.scanned = true
+	addedCount, updatedCount, removedCount := pm.publishChanges(eventBase, packs)
+	pm.recordSuperseded(previousPacks)
+	pm.evictStaleArtifacts()
+	pm.mu.Unlock()
+
+	scansTotal.Inc()
+	scanDuration.Observe(time.Since(startTime).Seconds())
+	pm.events.publish(EventScanCompleted, map[string]interface{}{
+		"count":       len(packs),
+		"added":       addedCount,
+		"updated":     updatedCount,
+		"removed":     removedCount,
+		"duration_ms": time.Since(startTime).Milliseconds(),
+	})
+	pm.logger.Info("扫描完成", zap.Int("count", len(packs)), zap.Duration("duration", time.Since(startTime)))
 	return nil
 }
 
@@ -191,8 +326,7 @@ func (pm *PacksManager) loadZipPack(packPath string) (*ResourcePack, error) {
 	}
 
 	name := strings.TrimSuffix(filepath.Base(packPath), ".zip")
-	description := fmt.Sprintf("Resource Pack: %s", name)
-	packFormat := 22
+	var mcmeta *PackMcmeta
 
 	if reader, err := zip.OpenReader(packPath); err == nil {
 		defer reader.Close()
@@ -200,10 +334,7 @@ func (pm *PacksManager) loadZipPack(packPath string) (*ResourcePack, error) {
 			if file.Name == "pack.mcmeta" {
 				if rc, err := file.Open(); err == nil {
 					if content, err := io.ReadAll(rc); err == nil {
-						if packInfo := pm.parsePackMcmeta(string(content)); packInfo != nil {
-							description = packInfo.Description
-							packFormat = packInfo.PackFormat
-						}
+						mcmeta = pm.parsePackMcmeta(name, content)
 					}
 					rc.Close()
 				}
@@ -211,105 +342,86 @@ func (pm *PacksManager) loadZipPack(packPath string) (*ResourcePack, error) {
 			}
 		}
 	}
+	description, packFormat := describePack(name, mcmeta)
 
-	hash, err := pm.calculateFileHash(packPath)
+	digests, err := calculateFileDigests(packPath)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ResourcePack{
+	rp := &ResourcePack{
 		Name:         name,
 		Path:         packPath,
 		Description:  description,
 		PackFormat:   packFormat,
 		Size:         stat.Size(),
-		Hash:         hash,
+		Hash:         digests.MD5,
+		SHA1:         digests.SHA1,
+		SHA256:       digests.SHA256,
 		LastModified: stat.ModTime(),
 		IsDirectory:  false,
-	}, nil
+		Mcmeta:       mcmeta,
+	}
+	rp.MinecraftVersions = pm.formatTable.VersionRange(rp.SupportedFormats())
+	return rp, nil
 }
 
 func (pm *PacksManager) loadDirectoryPack(dirPath string) (*ResourcePack, error) {
 	name := filepath.Base(dirPath)
-	description := fmt.Sprintf("Resource Pack: %s", name)
-	packFormat := 22
+	var mcmeta *PackMcmeta
 
 	packMcmetaPath := filepath.Join(dirPath, "pack.mcmeta")
 	if content, err := os.ReadFile(packMcmetaPath); err == nil {
-		if packInfo := pm.parsePackMcmeta(string(content)); packInfo != nil {
-			description = packInfo.Description
-			packFormat = packInfo.PackFormat
-		}
-	}
-
-	size, err := pm.calculateDirectorySize(dirPath)
-	if err != nil {
-		return nil, err
+		mcmeta = pm.parsePackMcmeta(name, content)
 	}
+	description, packFormat := describePack(name, mcmeta)
 
-	hash, err := pm.calculateDirectoryHash(dirPath)
+	// 在扫描时生成确定性的 ZIP，对外公布的 Hash 与大小均取自该文件
+	artifact, err := pm.directoryArtifact(name, dirPath)
 	if err != nil {
 		return nil, err
 	}
 
-	stat, err := os.Stat(dirPath)
+	lastModified, err := pm.calculateDirectoryModTime(dirPath)
 	if err != nil {
 		return nil, err
 	}
 
-	return &ResourcePack{
+	rp := &ResourcePack{
 		Name:         name,
 		Path:         dirPath,
 		Description:  description,
 		PackFormat:   packFormat,
-		Size:         size,
-		Hash:         hash,
-		LastModified: stat.ModTime(),
+		Size:         artifact.size,
+		Hash:         artifact.digests.MD5,
+		SHA1:         artifact.digests.SHA1,
+		SHA256:       artifact.digests.SHA256,
+		LastModified: lastModified,
 		IsDirectory:  true,
-	}, nil
+		Mcmeta:       mcmeta,
+	}
+	rp.MinecraftVersions = pm.formatTable.VersionRange(rp.SupportedFormats())
+	return rp, nil
 }
 
-func (pm *PacksManager) parsePackMcmeta(content string) *PackInfo {
-	var data map[string]interface{}
-	if err := json.Unmarshal([]byte(content), &data); err != nil {
+func (pm *PacksManager) parsePackMcmeta(name string, content []byte) *PackMcmeta {
+	mcmeta, err := ParsePackMcmeta(content)
+	if err != nil {
+		pm.logger.Warn("资源包 pack.mcmeta 无效", zap.String("name", name), zap.Error(err))
 		return nil
 	}
-
-	if pack, ok := data["pack"].(map[string]interface{}); ok {
-		description := ""
-		if desc, ok := pack["description"].(string); ok {
-			description = desc
-		}
-
-		packFormat := 22
-		if format, ok := pack["pack_format"].(float64); ok {
-			packFormat = int(format)
-		}
-
-		return &PackInfo{
-			Description: description,
-			PackFormat:  packFormat,
-		}
-	}
-
-	return nil
+	return mcmeta
 }
 
-func (pm *PacksManager) calculateDirectorySize(dirPath string) (int64, error) {
-	var totalSize int64
-	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() {
-			totalSize += info.Size()
-		}
-		return nil
-	})
-	return totalSize, err
+func describePack(name string, mcmeta *PackMcmeta) (string, int) {
+	if mcmeta == nil {
+		return fmt.Sprintf("Resource Pack: %s", name), 0
+	}
+	return mcmeta.Pack.Description.PlainText(), mcmeta.Format()
 }
 
-func (pm *PacksManager) calculateDirectoryHash(dirPath string) (string, error) {
+// calculateDirectoryFingerprint 仅用于判断目录内容是否变化（是否需要重新打包、能否复用扫描结果），不对外公布
+func (pm *PacksManager) calculateDirectoryFingerprint(dirPath string) (string, error) {
 	var fileInfos []string
 	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -317,7 +429,7 @@ func (pm *PacksManager) calculateDirectoryHash(dirPath string) (string, error) {
 		}
 		if !info.IsDir() {
 			relPath, _ := filepath.Rel(dirPath, path)
-			fileInfos = append(fileInfos, fmt.Sprintf("%s:%d:%d", relPath, info.ModTime().Unix(), info.Size()))
+			fileInfos = append(fileInfos, fmt.Sprintf("%s:%d:%d:%d", relPath, info.ModTime().UnixNano(), info.Size(), fileInode(info)))
 		}
 		return nil
 	})
@@ -331,19 +443,19 @@ func (pm *PacksManager) calculateDirectoryHash(dirPath string) (string, error) {
 	return fmt.Sprintf("%x", hash), nil
 }
 
-func (pm *PacksManager) calculateFileHash(filePath string) (string, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	hash := md5.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return "", err
-	}
-
-	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+// calculateDirectoryModTime 返回目录中最新的文件修改时间，子目录中的修改也会体现
+func (pm *PacksManager) calculateDirectoryModTime(dirPath string) (time.Time, error) {
+	var latest time.Time
+	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest, err
 }
 
 func (pm *PacksManager) GetPack(name string) *ResourcePack {
@@ -363,6 +475,53 @@ func (pm *PacksManager) GetAllPacks() []*ResourcePack {
 	return packs
 }
 
+// GetPacksForVersion 返回声明支持指定 Minecraft 版本的资源包
+func (pm *PacksManager) GetPacksForVersion(mcVersion string) ([]*ResourcePack, error) {
+	format, err := pm.formatTable.FormatForVersion(mcVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var packs []*ResourcePack
+	for _, pack := range pm.GetAllPacks() {
+		if pack.SupportsFormat(format) {
+			packs = append(packs, pack)
+		}
+	}
+	return packs, nil
+}
+
+// ValidatePack 校验资源包当前版本，结果按 SHA-1 缓存
+func (pm *PacksManager) ValidatePack(name string) (*ValidationReport, error) {
+	pack := pm.GetPack(name)
+	if pack == nil {
+		return nil, ErrPackNotFound
+	}
+
+	pm.validationsMu.Lock()
+	report, ok := pm.validations[pack.SHA1]
+	pm.validationsMu.Unlock()
+	if ok {
+		return report, nil
+	}
+
+	report, err := ValidatePackPath(pack.Path)
+	if err != nil {
+		return nil, err
+	}
+	report.Pack = pack.Name
+	report.SHA1 = pack.SHA1
+
+	pm.validationsMu.Lock()
+	pm.validations[pack.SHA1] = report
+	pm.validationsMu.Unlock()
+	return report, nil
+}
+
+func (pm *PacksManager) GetFormatTable() *FormatTable {
+	return pm.formatTable
+}
+
 func (pm *PacksManager) GetPackHash(name string) string {
 	pack := pm.GetPack(name)
 	if pack != nil {
@@ -371,12 +530,24 @@ func (pm *PacksManager) GetPackHash(name string) string {
 	return ""
 }
 
+func (pm *PacksManager) GetPackDigest(name, algo string) (string, bool) {
+	pack := pm.GetPack(name)
+	if pack == nil {
+		return "", false
+	}
+	return pack.Digests().Get(algo)
+}
+
 func (pm *PacksManager) startFileMonitoring() error {
+	if pm.config.FileMonitorMode == FileMonitorModePoll {
+		pm.startPolling()
+		return nil
+	}
+
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return err
 	}
-
 	pm.fileWatcher = watcher
 
 	go func() {
@@ -398,7 +569,7 @@ func (pm *PacksManager) startFileMonitoring() error {
 		}
 	}()
 
-	if err := watcher.Add(pm.packsDirectory); err != nil {
+	if err := pm.watchRecursive(pm.packsDirectory); err != nil {
 		return err
 	}
 
@@ -406,84 +577,35 @@ func (pm *PacksManager) startFileMonitoring() error {
 	return nil
 }
 
+// handleFileEvent 只重新加载发生变化的资源包，资源包目录本身是资源包时退回完整扫描。
+// 事件只交给防抖器，不在监控协程中等待。
 func (pm *PacksManager) handleFileEvent(event fsnotify.Event) {
-	if time.Since(pm.lastScanTime) < pm.scanCooldown {
-		return
-	}
+	watcherEvents.Inc(strings.ToLower(event.Op.String()))
 
-	time.Sleep(500 * time.Millisecond)
-
-	if err := pm.scanPacks(); err != nil {
-		pm.logger.Error("文件变化后扫描失败", zap.Error(err))
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := pm.watchRecursive(event.Name); err != nil {
+				pm.logger.Warn("添加目录监控失败", zap.String("path", event.Name), zap.Error(err))
+			}
+		}
 	}
+
+	pm.queueReload(event.Name)
 }
 
 func (pm *PacksManager) StopFileMonitoring() {
-	if pm.fileWatcher != nil {
+	pm.debouncer.stop()
+	if pm.config.FileMonitor {
 		close(pm.fileMonitorStop)
-		pm.fileWatcher.Close()
+		if pm.fileWatcher != nil {
+			pm.fileWatcher.Close()
+		}
 		pm.logger.Info("文件监控已停止")
 	}
 
 	pm.cleanupAllZipCache()
 }
 
-func (pm *PacksManager) CreateZipFromDirectory(dirPath, packName string) (string, error) {
-	pm.zipCacheMutex.RLock()
-	zipPath, ok := pm.zipCache[packName]
-	pm.zipCacheMutex.RUnlock()
-
-	if ok {
-		return zipPath, nil
-	}
-
-	pm.zipCacheMutex.Lock()
-	defer pm.zipCacheMutex.Unlock()
-
-	zipPath = filepath.Join(pm.tempDir, fmt.Sprintf("%s_%d.zip", packName, time.Now().Unix()))
-
-	zipFile, err := os.Create(zipPath)
-	if err != nil {
-		return "", err
-	}
-	defer zipFile.Close()
-
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
-
-	err = filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			return nil
-		}
-
-		relPath, _ := filepath.Rel(dirPath, path)
-		file, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer file.Close()
-
-		zipEntry, err := zipWriter.Create(relPath)
-		if err != nil {
-			return err
-		}
-
-		_, err = io.Copy(zipEntry, file)
-		return err
-	})
-
-	if err != nil {
-		return "", err
-	}
-
-	pm.logger.Info("已创建临时文件", zap.String("path", zipPath))
-	pm.zipCache[packName] = zipPath
-	return zipPath, nil
-}
-
 func (pm *PacksManager) GetPacksDirectory() string {
 	return pm.packsDirectory
 }
@@ -491,27 +613,3 @@ func (pm *PacksManager) GetPacksDirectory() string {
 func (pm *PacksManager) RescanPacks() error {
 	return pm.scanPacks()
 }
-
-func (pm *PacksManager) cleanupZipCache(removedPacks []string) {
-	pm.zipCacheMutex.Lock()
-	defer pm.zipCacheMutex.Unlock()
-
-	for _, packName := range removedPacks {
-		if zipPath, exists := pm.zipCache[packName]; exists {
-			if err := os.Remove(zipPath); err != nil {
-				pm.logger.Warn("删除临时文件失败", zap.String("path", zipPath), zap.Error(err))
-			} else {
-				pm.logger.Info("已删除临时文件", zap.String("path", zipPath))
-			}
-			delete(pm.zipCache, packName)
-		}
-	}
-}
-
-func (pm *PacksManager) cleanupAllZipCache() {
-	var removedPacks []string
-	for packName := range pm.zipCache {
-		removedPacks = append(removedPacks, packName)
-	}
-	pm.cleanupZipCache(removedPacks)
-}
@@ -0,0 +1,104 @@
+package pack
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	FileMonitorModeFsnotify = "fsnotify"
+	FileMonitorModePoll     = "poll"
+)
+
+type fileState struct {
+	size    int64
+	modTime int64
+	inode   uint64
+	isDir   bool
+}
+
+// startPolling 定期遍历资源包目录并比较文件的大小、修改时间与 inode，
+// 用于 NFS、SMB 等 fsnotify 收不到事件的文件系统。检测到的变化与 fsnotify 一样交给防抖器。
+func (pm *PacksManager) startPolling() {
+	interval := pm.config.FileMonitorInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	previous := pm.snapshotTree()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				current := pm.snapshotTree()
+				pm.diffSnapshots(previous, current)
+				previous = current
+			case <-pm.fileMonitorStop:
+				return
+			}
+		}
+	}()
+
+	pm.logger.Info("文件监控已启动（轮询）", zap.String("directory", pm.packsDirectory), zap.Duration("interval", interval))
+}
+
+// snapshotTree 记录资源包目录下所有文件与目录的状态，与 watchRecursive 一样跳过以 . 开头的目录
+func (pm *PacksManager) snapshotTree() map[string]fileState {
+	snapshot := make(map[string]fileState)
+	filepath.WalkDir(pm.packsDirectory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			pm.logger.Warn("轮询文件状态失败", zap.String("path", path), zap.Error(err))
+			return nil
+		}
+		if path == pm.packsDirectory {
+			return nil
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		snapshot[path] = fileState{
+			size:    info.Size(),
+			modTime: info.ModTime().UnixNano(),
+			inode:   fileInode(info),
+			isDir:   d.IsDir(),
+		}
+		return nil
+	})
+	return snapshot
+}
+
+func (pm *PacksManager) diffSnapshots(previous, current map[string]fileState) {
+	for path, state := range current {
+		old, ok := previous[path]
+		switch {
+		case !ok:
+			pm.handlePolledChange(path, "create")
+		case old != state:
+			pm.handlePolledChange(path, "write")
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			pm.handlePolledChange(path, "remove")
+		}
+	}
+}
+
+func (pm *PacksManager) handlePolledChange(path, op string) {
+	watcherEvents.Inc(op)
+	pm.queueReload(path)
+}
@@ -0,0 +1,279 @@
+package pack
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	MaxPreviewSize       = 512
+	maxPreviewCacheItems = 4096
+	maxPreviewCacheBytes = 64 * 1024 * 1024
+)
+
+var ErrImageNotFound = errors.New("图片不存在")
+
+// ErrImageTooLarge 表示贴图尺寸超过 maxTextureSize，解码会占用过多内存，不生成缩略图
+var ErrImageTooLarge = errors.New("图片尺寸过大，无法生成缩略图")
+
+type PackContents struct {
+	HasIcon    bool               `json:"has_icon"`
+	Files      int                `json:"files"`
+	Namespaces []NamespaceSummary `json:"namespaces"`
+}
+
+type NamespaceSummary struct {
+	Name         string   `json:"name"`
+	Textures     int      `json:"textures"`
+	Models       int      `json:"models"`
+	Blockstates  int      `json:"blockstates"`
+	Sounds       int      `json:"sounds"`
+	Lang         int      `json:"lang"`
+	Other        int      `json:"other"`
+	TexturePaths []string `json:"texture_paths"`
+}
+
+// previewCache 缓存资源包内容摘要与缩放后的图片，键中包含 SHA-1，资源包更新后自然失效。
+// 图片缓存按数量与总字节数限制，超出时整体清空。
+type previewCache struct {
+	mu         sync.Mutex
+	contents   map[string]*PackContents
+	images     map[string][]byte
+	imageBytes int
+}
+
+func newPreviewCache() *previewCache {
+	return &previewCache{
+		contents: make(map[string]*PackContents),
+		images:   make(map[string][]byte),
+	}
+}
+
+func (pc *previewCache) getImage(key string) ([]byte, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	data, ok := pc.images[key]
+	return data, ok
+}
+
+func (pc *previewCache) putImage(key string, data []byte) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	if len(data) > maxPreviewCacheBytes {
+		return
+	}
+	if len(pc.images) >= maxPreviewCacheItems || pc.imageBytes+len(data) > maxPreviewCacheBytes {
+		pc.images = make(map[string][]byte)
+		pc.imageBytes = 0
+	}
+	if old, ok := pc.images[key]; ok {
+		pc.imageBytes -= len(old)
+	}
+	pc.images[key] = data
+	pc.imageBytes += len(data)
+}
+
+func (pm *PacksManager) GetPackContents(name string) (*PackContents, error) {
+	pack := pm.GetPack(name)
+	if pack == nil {
+		return nil, ErrPackNotFound
+	}
+
+	pm.previews.mu.Lock()
+	contents, ok := pm.previews.contents[pack.SHA1]
+	pm.previews.mu.Unlock()
+	if ok {
+		return contents, nil
+	}
+
+	fsys, close, err := OpenPackFS(pack.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer close()
+
+	contents, err = summarizePack(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	pm.previews.mu.Lock()
+	if len(pm.previews.contents) >= maxPreviewCacheItems {
+		pm.previews.contents = make(map[string]*PackContents)
+	}
+	pm.previews.contents[pack.SHA1] = contents
+	pm.previews.mu.Unlock()
+	return contents, nil
+}
+
+func summarizePack(fsys fs.FS) (*PackContents, error) {
+	contents := &PackContents{Namespaces: []NamespaceSummary{}}
+	namespaces := make(map[string]*NamespaceSummary)
+
+	err := fs.WalkDir(fsys, ".", func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		contents.Files++
+		if filePath == "pack.png" {
+			contents.HasIcon = true
+		}
+
+		parts := strings.SplitN(filePath, "/", 4)
+		if len(parts) < 3 || parts[0] != "assets" {
+			return nil
+		}
+
+		summary, ok := namespaces[parts[1]]
+		if !ok {
+			summary = &NamespaceSummary{Name: parts[1], TexturePaths: []string{}}
+			namespaces[parts[1]] = summary
+		}
+
+		switch {
+		case parts[2] == "textures" && strings.HasSuffix(filePath, ".png"):
+			summary.Textures++
+			summary.TexturePaths = append(summary.TexturePaths, filePath)
+		case parts[2] == "models":
+			summary.Models++
+		case parts[2] == "blockstates":
+			summary.Blockstates++
+		case parts[2] == "sounds" || filePath == path.Join("assets", parts[1], "sounds.json"):
+			summary.Sounds++
+		case parts[2] == "lang":
+			summary.Lang++
+		default:
+			summary.Other++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, summary := range namespaces {
+		sort.Strings(summary.TexturePaths)
+		contents.Namespaces = append(contents.Namespaces, *summary)
+	}
+	sort.Slice(contents.Namespaces, func(i, j int) bool {
+		return contents.Namespaces[i].Name < contents.Namespaces[j].Name
+	})
+	return contents, nil
+}
+
+// GetPackImage 读取资源包中的 pack.png 或贴图，size 大于 0 时缩放到不超过 size×size 的 PNG，为 0 时返回原图。
+// 动画贴图只取第一帧。
+func (pm *PacksManager) GetPackImage(name, imagePath string, size int) ([]byte, error) {
+	pack := pm.GetPack(name)
+	if pack == nil {
+		return nil, ErrPackNotFound
+	}
+	if !isPreviewablePath(imagePath) {
+		return nil, ErrImageNotFound
+	}
+	if size < 0 || size > MaxPreviewSize {
+		return nil, fmt.Errorf("缩放尺寸必须在 0 到 %d 之间，0 表示原图", MaxPreviewSize)
+	}
+
+	key := fmt.Sprintf("%s:%s:%d", pack.SHA1, imagePath, size)
+	if data, ok := pm.previews.getImage(key); ok {
+		return data, nil
+	}
+
+	fsys, close, err := OpenPackFS(pack.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer close()
+
+	original, err := fs.ReadFile(fsys, imagePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrImageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 原图大小不受限制，不进入缓存
+	if size == 0 {
+		return original, nil
+	}
+
+	data, err := resizePNG(original, size)
+	if err != nil {
+		return nil, err
+	}
+	pm.previews.putImage(key, data)
+	return data, nil
+}
+
+func isPreviewablePath(imagePath string) bool {
+	if imagePath == "pack.png" {
+		return true
+	}
+	if !fs.ValidPath(imagePath) || !strings.HasSuffix(imagePath, ".png") {
+		return false
+	}
+	parts := strings.SplitN(imagePath, "/", 4)
+	return len(parts) == 4 && parts[0] == "assets" && parts[2] == "textures"
+}
+
+// resizePNG 解码前先读取 PNG 头部的尺寸，宽度超过 maxTextureSize 或像素数超过
+// maxTextureSize×maxTextureSize 的图片直接拒绝，竖向排列的动画贴图在像素数限制内仍可预览
+func resizePNG(data []byte, size int) ([]byte, error) {
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解析 PNG 失败: %w", err)
+	}
+	if cfg.Width > maxTextureSize || int64(cfg.Width)*int64(cfg.Height) > maxTextureSize*maxTextureSize {
+		return nil, fmt.Errorf("%w: %dx%d", ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	src, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("解析 PNG 失败: %w", err)
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > 0 && height > width && height%width == 0 {
+		height = width
+	}
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("图片尺寸无效")
+	}
+
+	dstWidth, dstHeight := size, size
+	if width > height {
+		dstHeight = max(1, size*height/width)
+	} else if height > width {
+		dstWidth = max(1, size*width/height)
+	}
+
+	// 最近邻缩放，保持像素风格贴图的清晰边缘
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		sy := bounds.Min.Y + y*height/dstHeight
+		for x := 0; x < dstWidth; x++ {
+			sx := bounds.Min.X + x*width/dstWidth
+			dst.Set(x, y, src.At(sx, sy))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
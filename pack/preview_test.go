@@ -0,0 +1,69 @@
+package pack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withDeclaredSize 修改 IHDR 中声明的尺寸并重新计算 CRC，模拟声明超大尺寸的贴图
+func withDeclaredSize(data []byte, width, height uint32) []byte {
+	data = bytes.Clone(data)
+	binary.BigEndian.PutUint32(data[16:20], width)
+	binary.BigEndian.PutUint32(data[20:24], height)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestResizePNG(t *testing.T) {
+	small := encodeTestPNG(t, 16, 16)
+	tests := []struct {
+		name       string
+		data       []byte
+		wantWidth  int
+		wantHeight int
+		wantErr    error
+	}{
+		{name: "square", data: small, wantWidth: 48, wantHeight: 48},
+		{name: "animation strip uses first frame", data: encodeTestPNG(t, 16, 64), wantWidth: 48, wantHeight: 48},
+		{name: "wide", data: encodeTestPNG(t, 32, 16), wantWidth: 48, wantHeight: 24},
+		{name: "declared 30000x30000", data: withDeclaredSize(small, 30000, 30000), wantErr: ErrImageTooLarge},
+		{name: "declared too wide", data: withDeclaredSize(small, maxTextureSize+1, 1), wantErr: ErrImageTooLarge},
+		{name: "declared too many frames", data: withDeclaredSize(small, maxTextureSize, maxTextureSize+1), wantErr: ErrImageTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := resizePNG(tt.data, 48)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("resizePNG() = %v，期望 %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := png.DecodeConfig(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.Width != tt.wantWidth || cfg.Height != tt.wantHeight {
+				t.Fatalf("尺寸 = %dx%d，期望 %dx%d", cfg.Width, cfg.Height, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
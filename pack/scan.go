@@ -0,0 +1,93 @@
+package pack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+type packCandidate struct {
+	name  string
+	path  string
+	isDir bool
+}
+
+// findPackCandidates 列出资源包目录中的资源包：根目录本身、包含 pack.mcmeta 的子目录与 ZIP 文件。
+// 以 . 开头的条目（上传临时文件、删除中的目录等）不是资源包，与 packNameForPath 一致跳过。
+func (pm *PacksManager) findPackCandidates() ([]packCandidate, error) {
+	var candidates []packCandidate
+	if pm.isResourcePackDirectory(pm.packsDirectory) {
+		candidates = append(candidates, packCandidate{
+			name:  filepath.Base(pm.packsDirectory),
+			path:  pm.packsDirectory,
+			isDir: true,
+		})
+	}
+
+	entries, err := os.ReadDir(pm.packsDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("读取目录失败: %w", err)
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		entryPath := filepath.Join(pm.packsDirectory, entry.Name())
+		if entry.IsDir() {
+			if pm.isResourcePackDirectory(entryPath) {
+				candidates = append(candidates, packCandidate{name: entry.Name(), path: entryPath, isDir: true})
+			}
+		} else if strings.HasSuffix(entry.Name(), ".zip") {
+			candidates = append(candidates, packCandidate{name: strings.TrimSuffix(entry.Name(), ".zip"), path: entryPath})
+		}
+	}
+	return candidates, nil
+}
+
+// loadCandidates 使用固定数量的协程读取资源包，返回值与 candidates 一一对应，读取失败的为 nil
+func (pm *PacksManager) loadCandidates(candidates []packCandidate) []*ResourcePack {
+	workers := pm.config.ScanWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(candidates) {
+		workers = len(candidates)
+	}
+
+	results := make([]*ResourcePack, len(candidates))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				candidate := candidates[index]
+				rp, err := pm.loadPack(candidate.path, candidate.isDir)
+				if err != nil {
+					pm.logger.Warn("读取资源包失败", zap.String("path", candidate.path), zap.Error(err))
+					continue
+				}
+				results[index] = rp
+				if candidate.isDir {
+					pm.logger.Info("发现目录资源包", zap.String("name", rp.Name))
+				} else {
+					pm.logger.Info("发现ZIP资源包", zap.String("name", rp.Name))
+				}
+			}
+		}()
+	}
+
+	for index := range candidates {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
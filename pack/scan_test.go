@@ -0,0 +1,39 @@
+package pack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindPackCandidatesSkipsDotEntries(t *testing.T) {
+	dir := t.TempDir()
+	mcmeta := []byte(`{"pack":{"pack_format":15,"description":"test"}}`)
+	for _, name := range []string{"visible", ".deleting-old-123", ".hidden"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "pack.mcmeta"), mcmeta, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"pack.zip", ".foo.zip", ".pack.zip-123.tmp"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pm := &PacksManager{packsDirectory: dir}
+	candidates, err := pm.findPackCandidates()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]bool)
+	for _, candidate := range candidates {
+		got[candidate.name] = true
+	}
+	if len(got) != 2 || !got["visible"] || !got["pack"] {
+		t.Fatalf("candidates = %+v，期望只有 visible 与 pack", candidates)
+	}
+}
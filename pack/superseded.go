@@ -0,0 +1,117 @@
+package pack
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const maxSupersededRecords = 1024
+
+var (
+	ErrPackNotFound = errors.New("资源包不存在")
+	ErrVersionGone  = errors.New("该版本已被替换")
+)
+
+// supersededPack 记录被新版本替换或被移除的资源包。宽限期内的目录资源包仍可
+// 通过 Hash 下载，超过宽限期或原文件已被覆盖的版本返回 ErrVersionGone。
+type supersededPack struct {
+	pack         *ResourcePack
+	supersededAt time.Time
+}
+
+// recordSuperseded 比较扫描前后的资源包，记录 Hash 发生变化的旧版本，调用方需持有 pm.mu
+func (pm *PacksManager) recordSuperseded(previous map[string]*ResourcePack) {
+	now := time.Now()
+	for name, old := range previous {
+		if current, ok := pm.packs[name]; ok && current.SHA1 == old.SHA1 {
+			continue
+		}
+		pm.superseded[old.SHA1] = &supersededPack{pack: old, supersededAt: now}
+		pm.logger.Info("资源包版本已被替换", zap.String("name", name), zap.String("sha1", old.SHA1))
+	}
+
+	// 重新出现的版本不再视为已替换
+	for _, current := range pm.packs {
+		delete(pm.superseded, current.SHA1)
+	}
+
+	if len(pm.superseded) <= maxSupersededRecords {
+		return
+	}
+
+	records := make([]string, 0, len(pm.superseded))
+	for sha1 := range pm.superseded {
+		records = append(records, sha1)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return pm.superseded[records[i]].supersededAt.Before(pm.superseded[records[j]].supersededAt)
+	})
+	for _, sha1 := range records[:len(records)-maxSupersededRecords] {
+		delete(pm.superseded, sha1)
+	}
+}
+
+// inGracePeriod 判断旧版本是否仍可下载，只有目录资源包的 ZIP 产物会被保留
+func (pm *PacksManager) inGracePeriod(record *supersededPack) bool {
+	return record.pack.IsDirectory && time.Since(record.supersededAt) < pm.config.SupersededGracePeriod
+}
+
+// GetPackVersion 按名称与 SHA-1 查找资源包的某个确定版本
+func (pm *PacksManager) GetPackVersion(name, sha1 string) (*ResourcePack, error) {
+	sha1 = strings.ToLower(sha1)
+
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	if current, ok := pm.packs[name]; ok && current.SHA1 == sha1 {
+		return current, nil
+	}
+
+	if version, ok := pm.history.find(name, sha1); ok {
+		if rp, ok := pm.historicPack(name, version); ok {
+			return rp, nil
+		}
+	}
+
+	record, ok := pm.superseded[sha1]
+	if !ok || record.pack.Name != name {
+		return nil, ErrPackNotFound
+	}
+	if !pm.inGracePeriod(record) {
+		return nil, ErrVersionGone
+	}
+	return record.pack, nil
+}
+
+// GetBlob 按 SHA-1 查找任意资源包的对应版本
+func (pm *PacksManager) GetBlob(sha1 string) (*ResourcePack, error) {
+	sha1 = strings.ToLower(sha1)
+
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	for _, current := range pm.packs {
+		if current.SHA1 == sha1 {
+			return current, nil
+		}
+	}
+
+	if name, version, ok := pm.history.findBlob(sha1); ok {
+		if rp, ok := pm.historicPack(name, version); ok {
+			return rp, nil
+		}
+	}
+
+	record, ok := pm.superseded[sha1]
+	if !ok {
+		return nil, ErrPackNotFound
+	}
+	if !pm.inGracePeriod(record) {
+		return nil, ErrVersionGone
+	}
+	return record.pack, nil
+}
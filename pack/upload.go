@@ -0,0 +1,107 @@
+package pack
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidPackName = errors.New("资源包名称无效")
+	ErrPackConflict    = errors.New("资源包名称冲突")
+	ErrInvalidUpload   = errors.New("上传的文件不是有效的资源包")
+	ErrUploadTooLarge  = errors.New("上传的文件过大")
+)
+
+// 资源包名称同时也是文件名与 URL 路径段，不允许路径分隔符、空格、以 . 开头的隐藏文件与以 . 结尾的名称
+var packNamePattern = regexp.MustCompile(`^[A-Za-z0-9_\-]([A-Za-z0-9_\-.]*[A-Za-z0-9_\-])?$`)
+
+func ValidatePackName(name string) error {
+	if len(name) > 128 || !packNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidPackName, name)
+	}
+	return nil
+}
+
+// UploadPack 将 ZIP 资源包写入资源包目录。内容先写入同目录下的临时文件并校验，
+// 通过后原子重命名为 <name>.zip，随后立即更新内存中的资源包列表，不等待文件监控。
+// 返回的布尔值表示是否为新建资源包，uploader 记录在版本历史中。
+func (pm *PacksManager) UploadPack(name string, body io.Reader, uploader string) (*ResourcePack, bool, error) {
+	if err := ValidatePackName(name); err != nil {
+		return nil, false, err
+	}
+
+	pm.mutationMu.Lock()
+	defer pm.mutationMu.Unlock()
+
+	existing := pm.filesystemPack(name)
+	if existing != nil && existing.IsDirectory {
+		return nil, false, fmt.Errorf("%w: 已存在同名的目录资源包", ErrPackConflict)
+	}
+
+	limit := pm.config.MaxUploadSize
+	if limit > 0 {
+		body = io.LimitReader(body, limit+1)
+	}
+
+	// 临时文件以 . 开头，文件监控与扫描都会忽略
+	packPath := filepath.Join(pm.packsDirectory, name+".zip")
+	err := writeFileAtomicWith(packPath, 0644, func(file *os.File) error {
+		written, err := io.Copy(file, body)
+		if err != nil {
+			return fmt.Errorf("写入临时文件失败: %w", err)
+		}
+		if limit > 0 && written > limit {
+			return ErrUploadTooLarge
+		}
+		return checkUploadedZip(file.Name())
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	rp, err := pm.loadPack(packPath, false)
+	if err != nil {
+		return nil, false, err
+	}
+	pm.recordVersion(rp, uploader)
+	pm.updatePacks(map[string]*ResourcePack{name: rp})
+
+	pm.logger.Info("已上传资源包", zap.String("name", name), zap.String("sha1", rp.SHA1), zap.Int64("size", rp.Size))
+	return rp, existing == nil, nil
+}
+
+func checkUploadedZip(zipPath string) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidUpload, err)
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		if file.Name != "pack.mcmeta" {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidUpload, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidUpload, err)
+		}
+		if _, err := ParsePackMcmeta(content); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidUpload, err)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("%w: 根目录缺少 pack.mcmeta", ErrInvalidUpload)
+}
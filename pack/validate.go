@@ -0,0 +1,366 @@
+package pack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+const maxTextureSize = 2048
+
+var (
+	namespacePattern    = regexp.MustCompile(`^[a-z0-9_.-]+$`)
+	resourcePathPattern = regexp.MustCompile(`^[a-z0-9_./-]+$`)
+)
+
+type ValidationIssue struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+}
+
+type ValidationReport struct {
+	Pack         string            `json:"pack"`
+	SHA1         string            `json:"sha1,omitempty"`
+	Valid        bool              `json:"valid"`
+	Errors       int               `json:"errors"`
+	Warnings     int               `json:"warnings"`
+	CheckedFiles int               `json:"checked_files"`
+	Issues       []ValidationIssue `json:"issues"`
+}
+
+// validator 在 fs.FS 之上检查资源包内容，ZIP 与目录资源包共用同一套规则
+type validator struct {
+	fsys   fs.FS
+	files  map[string]bool
+	report *ValidationReport
+}
+
+// ValidatePackPath 校验 ZIP 文件或目录形式的资源包
+func ValidatePackPath(packPath string) (*ValidationReport, error) {
+	fsys, close, err := OpenPackFS(packPath)
+	if err != nil {
+		return nil, err
+	}
+	defer close()
+
+	return ValidateFS(fsys, strings.TrimSuffix(filepath.Base(packPath), ".zip"))
+}
+
+func ValidateFS(fsys fs.FS, name string) (*ValidationReport, error) {
+	v := &validator{
+		fsys:   fsys,
+		files:  make(map[string]bool),
+		report: &ValidationReport{Pack: name, Issues: []ValidationIssue{}},
+	}
+
+	err := fs.WalkDir(fsys, ".", func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			v.files[filePath] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	v.checkRoot()
+	for _, filePath := range v.sortedFiles() {
+		v.checkFile(filePath)
+	}
+
+	sort.SliceStable(v.report.Issues, func(i, j int) bool {
+		return v.report.Issues[i].Severity == SeverityError && v.report.Issues[j].Severity != SeverityError
+	})
+	v.report.CheckedFiles = len(v.files)
+	v.report.Valid = v.report.Errors == 0
+	return v.report, nil
+}
+
+func (v *validator) sortedFiles() []string {
+	files := make([]string, 0, len(v.files))
+	for filePath := range v.files {
+		files = append(files, filePath)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func (v *validator) add(severity, code, filePath, format string, args ...interface{}) {
+	v.report.Issues = append(v.report.Issues, ValidationIssue{
+		Severity: severity,
+		Code:     code,
+		Path:     filePath,
+		Message:  fmt.Sprintf(format, args...),
+	})
+	if severity == SeverityError {
+		v.report.Errors++
+	} else {
+		v.report.Warnings++
+	}
+}
+
+func (v *validator) checkRoot() {
+	if !v.files["pack.mcmeta"] {
+		for filePath := range v.files {
+			if path.Base(filePath) == "pack.mcmeta" && strings.Count(filePath, "/") == 1 {
+				v.add(SeverityError, "nested_root", filePath,
+					"pack.mcmeta 位于子目录 %s 中，压缩时应选择该目录内的文件而不是目录本身", path.Dir(filePath))
+				return
+			}
+		}
+		v.add(SeverityError, "missing_mcmeta", "pack.mcmeta", "缺少 pack.mcmeta")
+		return
+	}
+
+	content, err := fs.ReadFile(v.fsys, "pack.mcmeta")
+	if err != nil {
+		v.add(SeverityError, "invalid_mcmeta", "pack.mcmeta", "读取失败: %v", err)
+		return
+	}
+	if _, err := ParsePackMcmeta(content); err != nil {
+		v.add(SeverityError, "invalid_mcmeta", "pack.mcmeta", "%v", err)
+	}
+}
+
+func (v *validator) checkFile(filePath string) {
+	parts := strings.Split(filePath, "/")
+	if len(parts) < 3 || parts[0] != "assets" {
+		return
+	}
+
+	namespace := parts[1]
+	if !namespacePattern.MatchString(namespace) {
+		v.add(SeverityError, "invalid_namespace", filePath, "命名空间 %q 只能包含小写字母、数字、_、-、.", namespace)
+	}
+
+	resourcePath := strings.Join(parts[2:], "/")
+	if strings.ToLower(resourcePath) != resourcePath {
+		v.add(SeverityError, "uppercase_path", filePath, "资源路径包含大写字母，游戏将无法加载")
+	} else if !resourcePathPattern.MatchString(resourcePath) {
+		v.add(SeverityError, "invalid_path", filePath, "资源路径只能包含小写字母、数字、_、-、.、/")
+	}
+
+	switch {
+	case strings.HasSuffix(filePath, ".json"):
+		v.checkJSON(filePath, parts[2])
+	case strings.HasSuffix(filePath, ".png.mcmeta"):
+		v.checkAnimation(filePath)
+	case strings.HasSuffix(filePath, ".png") && parts[2] == "textures":
+		v.checkTexture(filePath, parts)
+	}
+}
+
+func (v *validator) checkJSON(filePath, category string) {
+	content, err := fs.ReadFile(v.fsys, filePath)
+	if err != nil {
+		v.add(SeverityError, "unreadable_file", filePath, "读取失败: %v", err)
+		return
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), &data); err != nil {
+		v.add(SeverityError, "invalid_json", filePath, "JSON 语法错误: %v", err)
+		return
+	}
+
+	switch category {
+	case "models":
+		v.checkModel(filePath, data)
+	case "blockstates":
+		v.checkBlockstate(filePath, data)
+	}
+}
+
+func (v *validator) checkModel(filePath string, data interface{}) {
+	model, ok := data.(map[string]interface{})
+	if !ok {
+		v.add(SeverityError, "invalid_model", filePath, "模型文件必须是 JSON 对象")
+		return
+	}
+
+	if parent, ok := model["parent"].(string); ok && !strings.HasPrefix(parent, "builtin/") {
+		v.checkReference(filePath, "missing_model", parent, "models", ".json")
+	}
+
+	textures, _ := model["textures"].(map[string]interface{})
+	for _, value := range textures {
+		texture, ok := value.(string)
+		if !ok || strings.HasPrefix(texture, "#") {
+			continue
+		}
+		v.checkReference(filePath, "missing_texture", texture, "textures", ".png")
+	}
+}
+
+func (v *validator) checkBlockstate(filePath string, data interface{}) {
+	var visit func(value interface{})
+	visit = func(value interface{}) {
+		switch node := value.(type) {
+		case map[string]interface{}:
+			if model, ok := node["model"].(string); ok {
+				v.checkReference(filePath, "missing_model", model, "models", ".json")
+			}
+			for _, child := range node {
+				visit(child)
+			}
+		case []interface{}:
+			for _, child := range node {
+				visit(child)
+			}
+		}
+	}
+	visit(data)
+}
+
+// checkReference 检查资源引用，未写命名空间时为 minecraft，其资源可能来自原版，无法判断是否缺失
+func (v *validator) checkReference(filePath, code, location, category, ext string) {
+	namespace, resourcePath := "minecraft", location
+	if index := strings.Index(location, ":"); index >= 0 {
+		namespace, resourcePath = location[:index], location[index+1:]
+	}
+	if namespace == "minecraft" {
+		return
+	}
+
+	target := path.Join("assets", namespace, category, resourcePath+ext)
+	if !v.files[target] {
+		v.add(SeverityError, code, filePath, "引用的资源 %s 不存在 (%s)", location, target)
+	}
+}
+
+func (v *validator) textureSize(filePath string) (image.Config, error) {
+	file, err := v.fsys.Open(filePath)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer file.Close()
+
+	cfg, _, err := image.DecodeConfig(file)
+	return cfg, err
+}
+
+func (v *validator) checkTexture(filePath string, parts []string) {
+	cfg, err := v.textureSize(filePath)
+	if err != nil {
+		v.add(SeverityError, "invalid_texture", filePath, "无法解析 PNG: %v", err)
+		return
+	}
+
+	if cfg.Width > maxTextureSize || cfg.Height > maxTextureSize {
+		v.add(SeverityWarning, "texture_oversized", filePath, "贴图尺寸 %dx%d 超过 %d，可能导致显存占用过高", cfg.Width, cfg.Height, maxTextureSize)
+	}
+
+	// 只有会被拼接进图集的方块与物品贴图要求边长为 2 的幂
+	if len(parts) < 4 || (parts[3] != "block" && parts[3] != "item") {
+		return
+	}
+	height := cfg.Height
+	if v.files[filePath+".mcmeta"] && cfg.Width > 0 && cfg.Height%cfg.Width == 0 {
+		height = cfg.Width
+	}
+	if !isPowerOfTwo(cfg.Width) || !isPowerOfTwo(height) {
+		v.add(SeverityWarning, "texture_not_power_of_two", filePath, "贴图尺寸 %dx%d 不是 2 的幂，可能导致 mipmap 异常", cfg.Width, cfg.Height)
+	}
+}
+
+type animationMeta struct {
+	Animation *struct {
+		Frametime   *int          `json:"frametime"`
+		Width       *int          `json:"width"`
+		Height      *int          `json:"height"`
+		Interpolate *bool         `json:"interpolate"`
+		Frames      []interface{} `json:"frames"`
+	} `json:"animation"`
+}
+
+func (v *validator) checkAnimation(filePath string) {
+	content, err := fs.ReadFile(v.fsys, filePath)
+	if err != nil {
+		v.add(SeverityError, "unreadable_file", filePath, "读取失败: %v", err)
+		return
+	}
+
+	var meta animationMeta
+	if err := json.Unmarshal(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), &meta); err != nil {
+		v.add(SeverityError, "invalid_json", filePath, "JSON 语法错误: %v", err)
+		return
+	}
+
+	texturePath := strings.TrimSuffix(filePath, ".mcmeta")
+	if !v.files[texturePath] {
+		v.add(SeverityWarning, "orphan_mcmeta", filePath, "找不到对应的贴图 %s", texturePath)
+		return
+	}
+	if meta.Animation == nil {
+		return
+	}
+
+	animation := meta.Animation
+	if animation.Frametime != nil && *animation.Frametime <= 0 {
+		v.add(SeverityError, "invalid_animation", filePath, "frametime 必须为正整数")
+	}
+
+	cfg, err := v.textureSize(texturePath)
+	if err != nil {
+		return
+	}
+
+	frameWidth, frameHeight := cfg.Width, cfg.Width
+	if animation.Width != nil {
+		frameWidth = *animation.Width
+	}
+	if animation.Height != nil {
+		frameHeight = *animation.Height
+	}
+	if frameWidth <= 0 || frameHeight <= 0 || cfg.Width%frameWidth != 0 || cfg.Height%frameHeight != 0 {
+		v.add(SeverityError, "invalid_animation", filePath, "贴图尺寸 %dx%d 无法按 %dx%d 划分动画帧", cfg.Width, cfg.Height, frameWidth, frameHeight)
+		return
+	}
+
+	frameCount := (cfg.Width / frameWidth) * (cfg.Height / frameHeight)
+	for i, frame := range animation.Frames {
+		var index float64
+		switch f := frame.(type) {
+		case float64:
+			index = f
+		case map[string]interface{}:
+			value, ok := f["index"].(float64)
+			if !ok {
+				v.add(SeverityError, "invalid_animation", filePath, "第 %d 帧缺少 index", i)
+				continue
+			}
+			if t, ok := f["time"].(float64); ok && t <= 0 {
+				v.add(SeverityError, "invalid_animation", filePath, "第 %d 帧的 time 必须为正整数", i)
+			}
+			index = value
+		default:
+			v.add(SeverityError, "invalid_animation", filePath, "第 %d 帧格式无效", i)
+			continue
+		}
+		if index < 0 || int(index) >= frameCount {
+			v.add(SeverityError, "invalid_animation", filePath, "第 %d 帧引用的索引 %v 超出帧数 %d", i, index, frameCount)
+		}
+	}
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
@@ -0,0 +1,182 @@
+package pack
+
+import (
+	"archive/zip"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// watchRecursive 为目录及其所有子目录添加监控，fsnotify 本身不会递归监控子目录。
+// 以 . 开头的目录（上传、删除过程中的临时目录等）不会被监控。
+func (pm *PacksManager) watchRecursive(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 遍历过程中目录被删除
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return pm.fileWatcher.Add(path)
+	})
+}
+
+// packNameForPath 根据文件路径判断所属的资源包：资源包目录下的第一级目录或 ZIP 文件
+func (pm *PacksManager) packNameForPath(path string) (string, bool) {
+	relPath, err := filepath.Rel(pm.packsDirectory, path)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
+		return "", false
+	}
+
+	first := strings.SplitN(filepath.ToSlash(relPath), "/", 2)
+	if strings.HasPrefix(first[0], ".") {
+		return "", false
+	}
+	if len(first) == 1 && strings.HasSuffix(first[0], ".zip") {
+		return strings.TrimSuffix(first[0], ".zip"), true
+	}
+	if len(first) == 1 {
+		// 资源包目录下的普通文件不属于任何资源包，但可能是目录被删除或重命名
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return "", false
+		}
+	}
+	return first[0], true
+}
+
+// rescanPack 重新加载单个资源包，与完整扫描一样 ZIP 文件优先于同名目录
+func (pm *PacksManager) rescanPack(name string) error {
+	pm.mutationMu.Lock()
+	defer pm.mutationMu.Unlock()
+
+	startTime := time.Now()
+	var rp *ResourcePack
+	var err error
+
+	zipPath := filepath.Join(pm.packsDirectory, name+".zip")
+	dirPath := filepath.Join(pm.packsDirectory, name)
+	if info, statErr := os.Stat(zipPath); statErr == nil && !info.IsDir() {
+		rp, err = pm.loadPack(zipPath, false)
+	} else if pm.isResourcePackDirectory(dirPath) {
+		rp, err = pm.loadPack(dirPath, true)
+	} else {
+		pm.index.forget(zipPath, dirPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	// 回滚状态下比较的是资源包目录中的版本。文件状态未变化时 loadPack 返回同一个对象；
+	// 内容相同但文件被改写（touch 等）时仍需替换，否则修改时间过期会导致下载失败
+	current := pm.filesystemPack(name)
+	if current != nil && current == rp {
+		return nil
+	}
+	if rp == nil && pm.GetPack(name) == nil {
+		return nil
+	}
+
+	pm.updatePacks(map[string]*ResourcePack{name: rp})
+	switch {
+	case rp == nil:
+		pm.logger.Info("移除资源包", zap.String("name", name))
+	case current != nil && current.SHA1 == rp.SHA1:
+		pm.logger.Debug("资源包内容未变化，已更新文件信息", zap.String("name", name))
+	default:
+		pm.logger.Info("已重新加载资源包", zap.String("name", name), zap.String("sha1", rp.SHA1), zap.Duration("duration", time.Since(startTime)))
+	}
+	return nil
+}
+
+const (
+	// fullScanKey 是防抖器中代表完整扫描的键，资源包名称不会为空
+	fullScanKey = ""
+
+	stableCheckInterval = 200 * time.Millisecond
+	stableTimeout       = 30 * time.Second
+)
+
+// queueReload 将文件变化交给防抖器
+func (pm *PacksManager) queueReload(path string) {
+	if name, ok := pm.packNameForPath(path); ok {
+		pm.reloadPackLater(name)
+	}
+}
+
+// reloadPackLater 通过防抖器重新加载资源包，短时间内的多次请求只加载一次；
+// 资源包目录本身是资源包时退回完整扫描
+func (pm *PacksManager) reloadPackLater(name string) {
+	if pm.isResourcePackDirectory(pm.packsDirectory) {
+		name = fullScanKey
+	}
+	pm.debouncer.trigger(name)
+}
+
+// reloadAfterChange 由防抖器在文件静默后调用
+func (pm *PacksManager) reloadAfterChange(name string) {
+	if name == fullScanKey {
+		if err := pm.scanPacks(); err != nil {
+			pm.logger.Error("文件变化后扫描失败", zap.Error(err))
+		}
+		return
+	}
+
+	pm.waitForStable(name)
+	if err := pm.rescanPack(name); err != nil {
+		pm.logger.Error("文件变化后重新加载资源包失败", zap.String("name", name), zap.Error(err))
+	}
+}
+
+// waitForStable 等待资源包文件写入完成：两次检查之间大小与修改时间不变，
+// 且 ZIP 文件的中央目录可以读取。超时后仍会继续加载，由后续事件再次触发。
+func (pm *PacksManager) waitForStable(name string) {
+	deadline := time.Now().Add(stableTimeout)
+	previous := pm.packFileState(name)
+	for time.Now().Before(deadline) {
+		time.Sleep(stableCheckInterval)
+		current := pm.packFileState(name)
+		if current == previous && pm.packReadable(name) {
+			return
+		}
+		previous = current
+	}
+	pm.logger.Warn("等待资源包写入完成超时", zap.String("name", name))
+}
+
+func (pm *PacksManager) packFileState(name string) string {
+	zipPath := filepath.Join(pm.packsDirectory, name+".zip")
+	if info, err := os.Stat(zipPath); err == nil && !info.IsDir() {
+		return fmt.Sprintf("zip:%d:%d", info.Size(), info.ModTime().UnixNano())
+	}
+	fingerprint, err := pm.calculateDirectoryFingerprint(filepath.Join(pm.packsDirectory, name))
+	if err != nil {
+		return ""
+	}
+	return "dir:" + fingerprint
+}
+
+func (pm *PacksManager) packReadable(name string) bool {
+	zipPath := filepath.Join(pm.packsDirectory, name+".zip")
+	info, err := os.Stat(zipPath)
+	if err != nil || info.IsDir() {
+		return true
+	}
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return false
+	}
+	reader.Close()
+	return true
+}
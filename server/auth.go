@@ -0,0 +1,163 @@
+package server
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"resourcepack-server/config"
+)
+
+const (
+	roleRead     = "read"
+	roleDownload = "download"
+	roleSign     = "sign"
+	roleAdmin    = "admin"
+
+	contextKeyName = "auth_key_name"
+)
+
+type apiKey struct {
+	name  string
+	hash  []byte
+	roles map[string]bool
+}
+
+// authenticator 根据 [auth] 配置校验 API Key，配置中只保存 Key 的 SHA-256
+type authenticator struct {
+	enabled     bool
+	keys        []apiKey
+	publicRoles map[string]bool
+}
+
+func newAuthenticator(cfg config.AuthConfig) *authenticator {
+	a := &authenticator{
+		enabled:     cfg.Enabled,
+		publicRoles: toRoleSet(cfg.PublicRoles),
+	}
+	for _, key := range cfg.Keys {
+		hash, err := hex.DecodeString(strings.TrimPrefix(strings.ToLower(key.KeySHA256), "sha256:"))
+		if err != nil || len(hash) != sha256.Size {
+			continue
+		}
+		a.keys = append(a.keys, apiKey{
+			name:  key.Name,
+			hash:  hash,
+			roles: toRoleSet(key.Roles),
+		})
+	}
+	return a
+}
+
+func toRoleSet(roles []string) map[string]bool {
+	set := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		set[strings.ToLower(role)] = true
+	}
+	return set
+}
+
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// lookup 比较所有已配置的 Key，避免通过响应时间推断匹配位置
+func (a *authenticator) lookup(token string) *apiKey {
+	sum := sha256.Sum256([]byte(token))
+	var matched *apiKey
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], a.keys[i].hash) == 1 {
+			matched = &a.keys[i]
+		}
+	}
+	return matched
+}
+
+func requestToken(c *gin.Context) string {
+	if header := c.GetHeader("Authorization"); header != "" {
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	return c.GetHeader("X-API-Key")
+}
+
+func (k *apiKey) hasRole(role string) bool {
+	return k.roles[roleAdmin] || k.roles[role]
+}
+
+func (a *authenticator) hasKeyWithRole(role string) bool {
+	for i := range a.keys {
+		if a.keys[i].hasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// requireRole 要求请求方拥有指定角色。未启用认证时所有请求均放行；
+// 未携带凭据的请求拥有 public_roles 中的角色，admin 角色包含其余所有角色。
+func (s *Server) requireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.authorize(c, role, true) {
+			c.Next()
+		}
+	}
+}
+
+// requireKey 与 requireRole 相同，但无论是否启用认证都必须携带拥有该角色的 API Key，
+// 用于管理接口与签名链接，避免默认配置下这些接口对所有人开放
+func (s *Server) requireKey(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.authorize(c, role, false) {
+			c.Next()
+		}
+	}
+}
+
+// authorize 校验请求方的角色，失败时写入 401/403 响应并中止请求。
+// allowPublic 为 false 时必须携带拥有该角色的 API Key。
+func (s *Server) authorize(c *gin.Context, role string, allowPublic bool) bool {
+	if !s.auth.enabled && allowPublic {
+		return true
+	}
+
+	token := requestToken(c)
+	if token == "" {
+		if allowPublic && s.auth.publicRoles[role] {
+			return true
+		}
+		c.Header("WWW-Authenticate", `Bearer realm="resourcepack-server"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "需要提供 API Key",
+		})
+		return false
+	}
+
+	key := s.auth.lookup(token)
+	if key == nil {
+		c.Header("WWW-Authenticate", `Bearer realm="resourcepack-server", error="invalid_token"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "API Key 无效",
+		})
+		return false
+	}
+
+	if !key.hasRole(role) && !(allowPublic && s.auth.publicRoles[role]) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "没有访问该接口的权限",
+		})
+		return false
+	}
+
+	c.Set(contextKeyName, key.name)
+	return true
+}
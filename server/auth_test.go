@@ -0,0 +1,76 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"resourcepack-server/config"
+)
+
+// newRouteTestServer 只注册路由而没有 PacksManager，请求一旦到达处理函数就会因 panic 返回 500，
+// 用于确认请求在访问控制阶段就被拒绝
+func newRouteTestServer(cfg config.Config) *Server {
+	gin.SetMode(gin.TestMode)
+	s := &Server{
+		config: &cfg,
+		logger: zap.NewNop(),
+		router: gin.New(),
+		auth:   newAuthenticator(cfg.Auth),
+		signer: newURLSigner(cfg.Signing),
+	}
+	s.setupRoutes()
+	return s
+}
+
+func TestManagementRoutesRequireAdminKey(t *testing.T) {
+	keys := []config.APIKeyConfig{
+		{Name: "reader", KeySHA256: HashAPIKey("read-key"), Roles: []string{roleRead, roleDownload}},
+		{Name: "ci", KeySHA256: HashAPIKey("admin-key"), Roles: []string{roleAdmin}},
+	}
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{"PUT", "/api/packs/evil"},
+		{"DELETE", "/api/packs/victim"},
+		{"POST", "/api/packs/victim/rename"},
+		{"POST", "/api/packs/victim/rollback"},
+		{"PUT", "/api/packs/victim/channels/beta"},
+		{"POST", "/api/rescan"},
+		{"GET", "/debug"},
+		{"POST", "/api/webhooks/deliveries/1/redeliver"},
+	}
+	tests := []struct {
+		name string
+		auth config.AuthConfig
+		key  string
+		want int
+	}{
+		{"auth disabled without key", config.AuthConfig{}, "", http.StatusUnauthorized},
+		{"auth disabled with admin in public roles", config.AuthConfig{PublicRoles: []string{roleAdmin}}, "", http.StatusUnauthorized},
+		{"auth enabled without key", config.AuthConfig{Enabled: true, PublicRoles: []string{roleRead, roleDownload}}, "", http.StatusUnauthorized},
+		{"unknown key", config.AuthConfig{Keys: keys}, "guess", http.StatusUnauthorized},
+		{"key without admin role", config.AuthConfig{Keys: keys}, "read-key", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRouteTestServer(config.Config{Auth: tt.auth})
+			for _, route := range routes {
+				req := httptest.NewRequest(route.method, route.path, nil)
+				if tt.key != "" {
+					req.Header.Set("Authorization", "Bearer "+tt.key)
+				}
+				rec := httptest.NewRecorder()
+				s.router.ServeHTTP(rec, req)
+				if rec.Code != tt.want {
+					t.Errorf("%s %s = %d，期望 %d", route.method, route.path, rec.Code, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"resourcepack-server/pack"
+)
+
+const (
+	cacheControlRevalidate = "no-cache"
+	cacheControlImmutable  = "public, max-age=31536000, immutable"
+)
+
+func (s *Server) downloadPackHandler(c *gin.Context) {
+	name := c.Param("name")
+	if channel := c.Query("channel"); channel != "" {
+		s.downloadChannelHandler(c, name, channel)
+		return
+	}
+
+	resourcePack := s.packsManager.GetPack(name)
+	if resourcePack == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "资源包不存在",
+		})
+		return
+	}
+
+	s.servePack(c, resourcePack, false)
+}
+
+// servePack 发送资源包文件。ETag 为 SHA-1，Range、If-Range、If-None-Match 及
+// If-Modified-Since 由 http.ServeContent 处理。immutable 仅用于以 Hash 寻址的 URL。
+func (s *Server) servePack(c *gin.Context, resourcePack *pack.ResourcePack, immutable bool) {
+	artifactPath, err := s.packsManager.ArtifactPath(resourcePack)
+	if errors.Is(err, pack.ErrArtifactOutdated) {
+		c.Header("Retry-After", "5")
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"success": false,
+			"error":   "资源包正在更新，请稍后重试",
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Error("获取资源包文件失败", zap.String("name", resourcePack.Name), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "资源包文件生成失败",
+		})
+		return
+	}
+
+	file, err := os.Open(artifactPath)
+	if err != nil {
+		s.logger.Error("打开资源包文件失败", zap.String("path", artifactPath), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "资源包文件读取失败",
+		})
+		return
+	}
+	defer file.Close()
+
+	cacheControl := cacheControlRevalidate
+	if immutable {
+		cacheControl = cacheControlImmutable
+	}
+
+	c.Header("ETag", fmt.Sprintf("\"%s\"", resourcePack.SHA1))
+	c.Header("Cache-Control", cacheControl)
+	c.Header("Accept-Ranges", "bytes")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.zip\"", resourcePack.Name))
+	c.Header("Content-Type", "application/zip")
+	http.ServeContent(c.Writer, c.Request, resourcePack.Name+".zip", resourcePack.LastModified, file)
+	recordDownload(c, resourcePack.Name)
+	s.recordDownloadEvent(c, resourcePack)
+}
+
+// downloadChannelHandler 发送发布渠道当前指向的版本，渠道可能随时变化，因此不使用 immutable
+func (s *Server) downloadChannelHandler(c *gin.Context, name, channel string) {
+	resourcePack, err := s.packsManager.GetPackChannel(name, channel)
+	if errors.Is(err, pack.ErrChannelNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "发布渠道不存在",
+		})
+		return
+	}
+	if err != nil {
+		s.versionLookupError(c, err)
+		return
+	}
+
+	s.servePack(c, resourcePack, false)
+}
+
+func (s *Server) downloadPackVersionHandler(c *gin.Context) {
+	resourcePack, err := s.packsManager.GetPackVersion(c.Param("name"), c.Param("hash"))
+	if err != nil {
+		s.versionLookupError(c, err)
+		return
+	}
+
+	s.servePack(c, resourcePack, true)
+}
+
+func (s *Server) blobHandler(c *gin.Context) {
+	resourcePack, err := s.packsManager.GetBlob(c.Param("sha1"))
+	if err != nil {
+		s.versionLookupError(c, err)
+		return
+	}
+
+	s.servePack(c, resourcePack, true)
+}
+
+func (s *Server) versionLookupError(c *gin.Context, err error) {
+	if errors.Is(err, pack.ErrVersionGone) {
+		c.JSON(http.StatusGone, gin.H{
+			"success": false,
+			"error":   "该版本已被替换，请获取最新的下载地址",
+		})
+		return
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{
+		"success": false,
+		"error":   "资源包不存在",
+	})
+}
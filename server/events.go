@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"resourcepack-server/pack"
+)
+
+const eventsHeartbeatInterval = 15 * time.Second
+
+// eventsHandler 以 Server-Sent Events 推送资源包变化。客户端重连时通过 Last-Event-ID 请求头
+// 或 last_event_id 参数补发断线期间的事件；types 参数可以只订阅部分事件类型，多个类型以逗号分隔。
+func (s *Server) eventsHandler(c *gin.Context) {
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		var err error
+		if lastID, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "Last-Event-ID 无效",
+			})
+			return
+		}
+	}
+
+	var types map[string]bool
+	if filter := c.Query("types"); filter != "" {
+		types = make(map[string]bool)
+		for _, eventType := range strings.Split(filter, ",") {
+			types[strings.TrimSpace(eventType)] = true
+		}
+		types[pack.EventStreamReset] = true
+	}
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "当前连接不支持事件推送",
+		})
+		return
+	}
+
+	replay, events, cancel := s.packsManager.Subscribe(lastID)
+	defer cancel()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 3000\n\n")
+
+	for _, event := range replay {
+		if types == nil || types[event.Type] {
+			writeEvent(c, event)
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				// 推送过慢被断开，客户端会按 retry 自动重连并补发
+				return
+			}
+			if types == nil || types[event.Type] {
+				writeEvent(c, event)
+				flusher.Flush()
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+func writeEvent(c *gin.Context, event pack.Event) {
+	data, err := json.Marshal(gin.H{
+		"type": event.Type,
+		"time": event.Time.Unix(),
+		"data": event.Data,
+	})
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (s *Server) packVersionsHandler(c *gin.Context) {
+	name := c.Param("name")
+	versions, err := s.packsManager.GetPackVersions(name)
+	if err != nil {
+		s.manageError(c, "获取版本历史失败", name, err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(versions.Versions))
+	for _, version := range versions.Versions {
+		items = append(items, gin.H{
+			"sha1":         version.SHA1,
+			"sha256":       version.SHA256,
+			"md5":          version.MD5,
+			"size":         version.Size,
+			"pack_format":  version.PackFormat,
+			"description":  version.Description,
+			"uploader":     version.Uploader,
+			"created_at":   version.CreatedAt.Unix(),
+			"current":      version.SHA1 == versions.Current,
+			"download_url": fmt.Sprintf("/download/%s/%s", url.PathEscape(name), version.SHA1),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"name":        name,
+			"current":     versions.Current,
+			"rolled_back": versions.RolledBack,
+			"versions":    items,
+		},
+	})
+}
+
+type rollbackRequest struct {
+	SHA1 string `json:"sha1" form:"sha1" binding:"required"`
+}
+
+func (s *Server) rollbackPackHandler(c *gin.Context) {
+	name := c.Param("name")
+
+	var req rollbackRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "缺少 sha1",
+		})
+		return
+	}
+
+	resourcePack, err := s.packsManager.RollbackPack(name, strings.ToLower(req.SHA1))
+	if err != nil {
+		s.manageError(c, "回滚资源包失败", name, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    resourcePack.ToMap(),
+	})
+}
+
+func (s *Server) packChannelsHandler(c *gin.Context) {
+	name := c.Param("name")
+	channels, err := s.packsManager.GetChannels(name)
+	if err != nil {
+		s.manageError(c, "获取发布渠道失败", name, err)
+		return
+	}
+
+	items := make([]gin.H, 0, len(channels))
+	for _, channel := range channels {
+		items = append(items, gin.H{
+			"name":         channel.Name,
+			"sha1":         channel.SHA1,
+			"download_url": fmt.Sprintf("/download/%s?channel=%s", url.PathEscape(name), url.QueryEscape(channel.Name)),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"name":     name,
+			"channels": items,
+		},
+	})
+}
+
+func (s *Server) setChannelHandler(c *gin.Context) {
+	name := c.Param("name")
+
+	var req rollbackRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "缺少 sha1",
+		})
+		return
+	}
+
+	resourcePack, err := s.packsManager.SetChannel(name, c.Param("channel"), strings.ToLower(req.SHA1))
+	if err != nil {
+		s.manageError(c, "更新发布渠道失败", name, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    resourcePack.ToMap(),
+	})
+}
+
+func (s *Server) deleteChannelHandler(c *gin.Context) {
+	name := c.Param("name")
+	if err := s.packsManager.DeleteChannel(name, c.Param("channel")); err != nil {
+		s.manageError(c, "删除发布渠道失败", name, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+type promoteRequest struct {
+	From string `json:"from" form:"from" binding:"required"`
+	To   string `json:"to" form:"to" binding:"required"`
+}
+
+func (s *Server) promoteChannelHandler(c *gin.Context) {
+	name := c.Param("name")
+
+	var req promoteRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "缺少 from 或 to",
+		})
+		return
+	}
+
+	resourcePack, err := s.packsManager.PromoteChannel(name, req.From, req.To)
+	if err != nil {
+		s.manageError(c, "发布版本失败", name, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    resourcePack.ToMap(),
+	})
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"resourcepack-server/pack"
+)
+
+// uploadPackHandler 接收 multipart 表单中的文件字段，或直接以请求体上传的 ZIP
+func (s *Server) uploadPackHandler(c *gin.Context) {
+	name := c.Param("name")
+
+	body, err := uploadBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	resourcePack, created, err := s.packsManager.UploadPack(name, body, uploaderName(c))
+	if err != nil {
+		s.manageError(c, "上传资源包失败", name, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{
+		"success": true,
+		"data":    resourcePack.ToMap(),
+	})
+}
+
+func (s *Server) deletePackHandler(c *gin.Context) {
+	name := c.Param("name")
+	resourcePack, err := s.packsManager.DeletePack(name)
+	if err != nil {
+		s.manageError(c, "删除资源包失败", name, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    resourcePack.ToMap(),
+	})
+}
+
+type renamePackRequest struct {
+	NewName string `json:"new_name" form:"new_name" binding:"required"`
+}
+
+func (s *Server) renamePackHandler(c *gin.Context) {
+	name := c.Param("name")
+
+	var request renamePackRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "缺少 new_name 参数",
+		})
+		return
+	}
+
+	resourcePack, err := s.packsManager.RenamePack(name, request.NewName)
+	if err != nil {
+		s.manageError(c, "重命名资源包失败", name, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    resourcePack.ToMap(),
+	})
+}
+
+func uploadBody(c *gin.Context) (io.Reader, error) {
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return c.Request.Body, nil
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, errors.New("表单中缺少资源包文件")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FileName() != "" || part.FormName() == "file" {
+			return part, nil
+		}
+	}
+}
+
+// manageError 将资源包管理操作的错误映射为对应的 HTTP 状态码
+func (s *Server) manageError(c *gin.Context, message, name string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, pack.ErrPackNotFound), errors.Is(err, pack.ErrChannelNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, pack.ErrPackConflict):
+		status = http.StatusConflict
+	case errors.Is(err, pack.ErrInvalidPackName), errors.Is(err, pack.ErrInvalidUpload), errors.Is(err, pack.ErrInvalidChannel):
+		status = http.StatusBadRequest
+	case errors.Is(err, pack.ErrUploadTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, pack.ErrVersionGone):
+		status = http.StatusGone
+	case errors.Is(err, pack.ErrHistoryDisabled):
+		status = http.StatusNotImplemented
+	}
+
+	if status == http.StatusInternalServerError {
+		s.logger.Error(message, zap.String("name", name), zap.Error(err))
+		c.JSON(status, gin.H{
+			"success": false,
+			"error":   message,
+		})
+		return
+	}
+
+	c.JSON(status, gin.H{
+		"success": false,
+		"error":   strings.TrimSpace(err.Error()),
+	})
+}
+
+// uploaderName 返回记录在版本历史中的上传者，即请求使用的 API Key 名称
+func uploaderName(c *gin.Context) string {
+	if name := c.GetString(contextKeyName); name != "" {
+		return name
+	}
+	return "api"
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"resourcepack-server/metrics"
+)
+
+var (
+	requestDuration = metrics.NewHistogram("resourcepack_http_request_duration_seconds",
+		"HTTP 请求耗时，按路由统计", metrics.DefaultBuckets, "method", "route", "status")
+	downloadsTotal = metrics.NewCounter("resourcepack_downloads_total",
+		"资源包下载次数，Range 请求也计入", "pack")
+	downloadBytes = metrics.NewCounter("resourcepack_download_bytes_total",
+		"资源包下载发送的字节数", "pack")
+)
+
+// metricsMiddleware 记录请求耗时。SSE 等长连接也会在断开时计入，告警时应排除 /api/events
+func (s *Server) metricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		startTime := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		requestDuration.Observe(time.Since(startTime).Seconds(), c.Request.Method, route, strconv.Itoa(c.Writer.Status()))
+	}
+}
+
+// recordDownload 在资源包发送完成后统计下载次数与字节数，HEAD 与 304 不计入
+func recordDownload(c *gin.Context, name string) {
+	status := c.Writer.Status()
+	if c.Request.Method != http.MethodGet || (status != http.StatusOK && status != http.StatusPartialContent) {
+		return
+	}
+	downloadsTotal.Inc(name)
+	if size := c.Writer.Size(); size > 0 {
+		downloadBytes.Add(float64(size), name)
+	}
+}
+
+func (s *Server) metricsHandler(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	metrics.Default.WritePrometheus(c.Writer)
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"resourcepack-server/pack"
+)
+
+const maxThumbnailsPerNamespace = 300
+
+func (s *Server) packIconHandler(c *gin.Context) {
+	s.servePackImage(c, "pack.png")
+}
+
+func (s *Server) packTextureHandler(c *gin.Context) {
+	s.servePackImage(c, strings.TrimPrefix(c.Param("path"), "/"))
+}
+
+func (s *Server) servePackImage(c *gin.Context, imagePath string) {
+	size, err := strconv.Atoi(c.DefaultQuery("size", "0"))
+	if err != nil || size < 0 || size > pack.MaxPreviewSize {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   fmt.Sprintf("size 必须在 0 到 %d 之间，0 表示原图", pack.MaxPreviewSize),
+		})
+		return
+	}
+
+	name := c.Param("name")
+	data, err := s.packsManager.GetPackImage(name, imagePath, size)
+	if errors.Is(err, pack.ErrPackNotFound) || errors.Is(err, pack.ErrImageNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, pack.ErrImageTooLarge) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Error("读取资源包图片失败", zap.String("name", name), zap.String("path", imagePath), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "读取图片失败",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "image/png", data)
+}
+
+func (s *Server) packContentsHandler(c *gin.Context) {
+	name := c.Param("name")
+	contents, err := s.packsManager.GetPackContents(name)
+	if errors.Is(err, pack.ErrPackNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "资源包不存在",
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Error("读取资源包内容失败", zap.String("name", name), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "读取资源包内容失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    contents,
+	})
+}
+
+func (s *Server) packDetailPageHandler(c *gin.Context) {
+	name := c.Param("name")
+	resourcePack := s.packsManager.GetPack(name)
+	if resourcePack == nil {
+		c.String(http.StatusNotFound, "资源包不存在")
+		return
+	}
+
+	contents, err := s.packsManager.GetPackContents(name)
+	if err != nil {
+		s.logger.Error("读取资源包内容失败", zap.String("name", name), zap.Error(err))
+		c.String(http.StatusInternalServerError, "读取资源包内容失败")
+		return
+	}
+
+	escapedName := html.EscapeString(resourcePack.Name)
+	packURL := url.PathEscape(resourcePack.Name)
+	versions := "未知版本"
+	if resourcePack.MinecraftVersions != nil {
+		versions = "Minecraft " + resourcePack.MinecraftVersions.String()
+	}
+
+	htmlContent := fmt.Sprintf(`
+<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>%s - Minecraft 资源包服务器</title>
+    <style>
+        body { font-family: 'Microsoft YaHei', sans-serif; margin: 0; padding: 20px; background: #f5f5f5; }
+        .container { max-width: 1000px; margin: 0 auto; background: white; padding: 30px; border-radius: 10px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); }
+        .header { display: flex; align-items: center; gap: 20px; margin-bottom: 20px; }
+        .header img { width: 96px; height: 96px; image-rendering: pixelated; border-radius: 8px; background: #ecf0f1; }
+        h1 { color: #2c3e50; margin: 0 0 10px 0; }
+        .pack-desc { color: #7f8c8d; }
+        .pack-meta { font-size: 0.9em; color: #95a5a6; margin-bottom: 20px; }
+        table { border-collapse: collapse; width: 100%%; margin-bottom: 30px; }
+        th, td { border: 1px solid #ddd; padding: 8px; text-align: left; }
+        th { background: #fafafa; }
+        .textures { display: flex; flex-wrap: wrap; gap: 6px; margin-bottom: 20px; }
+        .textures img { width: 48px; height: 48px; image-rendering: pixelated; background: #ecf0f1; border-radius: 4px; }
+        .note { color: #95a5a6; font-size: 0.9em; }
+        a { color: #3498db; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <p><a href="/">&larr; 返回资源包列表</a></p>
+        <div class="header">
+            %s
+            <div>
+                <h1>%s</h1>
+                <div class="pack-desc">%s</div>
+            </div>
+        </div>
+        <div class="pack-meta">
+            格式: %d (%s) | 大小: %.2f MB | 文件数: %d<br>
+            SHA-1: %s<br>
+            <a href="/download/%s/%s">下载资源包</a> |
+            <a href="/api/packs/%s/validation">校验报告</a>
+        </div>
+        <h2>命名空间 (%d 个)</h2>
+        <table>
+            <tr><th>命名空间</th><th>贴图</th><th>模型</th><th>方块状态</th><th>声音</th><th>语言</th><th>其他</th></tr>
+`, escapedName,
+		func() string {
+			if !contents.HasIcon {
+				return ""
+			}
+			return fmt.Sprintf(`<img src="/api/packs/%s/icon?size=128" alt="pack.png">`, packURL)
+		}(),
+		escapedName, resourcePack.DescriptionHTML(), resourcePack.PackFormat, versions,
+		float64(resourcePack.Size)/1024/1024, contents.Files, resourcePack.SHA1,
+		packURL, resourcePack.SHA1, packURL, len(contents.Namespaces))
+
+	for _, namespace := range contents.Namespaces {
+		htmlContent += fmt.Sprintf(`            <tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td></tr>
+`, html.EscapeString(namespace.Name), namespace.Textures, namespace.Models, namespace.Blockstates,
+			namespace.Sounds, namespace.Lang, namespace.Other)
+	}
+	htmlContent += `        </table>
+`
+
+	for _, namespace := range contents.Namespaces {
+		if namespace.Textures == 0 {
+			continue
+		}
+		htmlContent += fmt.Sprintf(`        <h3>%s 贴图 (%d 个)</h3>
+        <div class="textures">
+`, html.EscapeString(namespace.Name), namespace.Textures)
+		for i, texturePath := range namespace.TexturePaths {
+			if i >= maxThumbnailsPerNamespace {
+				break
+			}
+			escapedPath := html.EscapeString(texturePath)
+			htmlContent += fmt.Sprintf(`            <img src="/api/packs/%s/textures/%s?size=48" title="%s" alt="%s" loading="lazy">
+`, packURL, html.EscapeString(escapeURLPath(texturePath)), escapedPath, escapedPath)
+		}
+		htmlContent += `        </div>
+`
+		if namespace.Textures > maxThumbnailsPerNamespace {
+			htmlContent += fmt.Sprintf(`        <p class="note">仅显示前 %d 个贴图</p>
+`, maxThumbnailsPerNamespace)
+		}
+	}
+
+	htmlContent += `
+    </div>
+</body>
+</html>
+`
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.String(http.StatusOK, htmlContent)
+}
+
+// escapeURLPath 逐段转义路径，保留分隔符 /
+func escapeURLPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
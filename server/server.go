@@ -1,26 +1,37 @@
 package server
 
 import (
+	"errors"
 	"fmt"
+	"html"
 	"net/http"
+	"net/url"
 	"resourcepack-server/pack"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
 	"resourcepack-server/config"
+	"resourcepack-server/metrics"
+	"resourcepack-server/stats"
+	"resourcepack-server/webhook"
 )
 
 type Server struct {
 	config       *config.Config
 	packsManager *pack.PacksManager
+	dispatcher   *webhook.Dispatcher
+	stats        *stats.Store
 	logger       *zap.Logger
 	router       *gin.Engine
+	auth         *authenticator
+	signer       *urlSigner
 }
 
-func NewServer(config *config.Config, packsManager *pack.PacksManager, logger *zap.Logger) *Server {
+func NewServer(config *config.Config, packsManager *pack.PacksManager, dispatcher *webhook.Dispatcher, statsStore *stats.Store, logger *zap.Logger) *Server {
 	if !config.Server.Debug {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -28,8 +39,20 @@ func NewServer(config *config.Config, packsManager *pack.PacksManager, logger *z
 	server := &Server{
 		config:       config,
 		packsManager: packsManager,
+		dispatcher:   dispatcher,
+		stats:        statsStore,
 		logger:       logger,
 		router:       gin.New(),
+		auth:         newAuthenticator(config.Auth),
+		signer:       newURLSigner(config.Signing),
+	}
+
+	metrics.NewGaugeFunc("resourcepack_packs", "当前提供的资源包数量", func() float64 {
+		return float64(len(packsManager.GetAllPacks()))
+	})
+
+	if !server.auth.hasKeyWithRole(roleAdmin) {
+		logger.Warn("未配置拥有 admin 角色的 API Key，上传、删除等管理接口不可用")
 	}
 
 	server.setupRoutes()
@@ -40,18 +63,65 @@ func (s *Server) setupRoutes() {
 	s.router.Use(gin.Logger())
 	s.router.Use(gin.Recovery())
 	s.router.Use(s.errorMiddleware())
+	s.router.Use(s.metricsMiddleware())
+
+	s.router.GET("/healthz", s.healthHandler)
+
+	read := s.router.Group("/", s.requireRole(roleRead))
+	read.GET("/", s.indexHandler)
+	read.GET("/api/packs", s.listPacksHandler)
+	read.GET("/api/packs/:name", s.getPackHandler)
+	read.GET("/api/packs/:name/validation", s.validatePackHandler)
+	read.GET("/api/packs/:name/icon", s.packIconHandler)
+	read.GET("/api/packs/:name/textures/*path", s.packTextureHandler)
+	read.GET("/api/packs/:name/contents", s.packContentsHandler)
+	read.GET("/api/packs/:name/versions", s.packVersionsHandler)
+	read.GET("/api/packs/:name/channels", s.packChannelsHandler)
+	read.GET("/api/packs/:name/stats", s.packStatsHandler)
+	read.GET("/api/formats", s.listFormatsHandler)
+	read.GET("/api/events", s.eventsHandler)
+	read.GET("/metrics", s.metricsHandler)
+	read.GET("/packs/:name", s.packDetailPageHandler)
+
+	download := s.router.Group("/", s.requireDownload())
+	download.GET("/download/:name", s.downloadPackHandler)
+	download.HEAD("/download/:name", s.downloadPackHandler)
+	download.GET("/download/:name/:hash", s.downloadPackVersionHandler)
+	download.HEAD("/download/:name/:hash", s.downloadPackVersionHandler)
+	download.GET("/blobs/:sha1", s.blobHandler)
+	download.HEAD("/blobs/:sha1", s.blobHandler)
+	s.router.GET("/hash/:name", s.requireRole(roleDownload), s.hashHandler)
+
+	s.router.POST("/api/packs/:name/signed-url", s.requireKey(roleSign), s.signURLHandler)
+
+	admin := s.router.Group("/", s.requireKey(roleAdmin))
+	admin.PUT("/api/packs/:name", s.uploadPackHandler)
+	admin.DELETE("/api/packs/:name", s.deletePackHandler)
+	admin.POST("/api/packs/:name/rename", s.renamePackHandler)
+	admin.POST("/api/packs/:name/rollback", s.rollbackPackHandler)
+	admin.PUT("/api/packs/:name/channels/:channel", s.setChannelHandler)
+	admin.DELETE("/api/packs/:name/channels/:channel", s.deleteChannelHandler)
+	admin.POST("/api/packs/:name/promote", s.promoteChannelHandler)
+	admin.GET("/api/rescan", s.rescanPacksHandler)
+	admin.POST("/api/rescan", s.rescanPacksHandler)
+	admin.GET("/debug", s.debugHandler)
+	admin.GET("/api/webhooks/deliveries", s.webhookDeliveriesHandler)
+	admin.POST("/api/webhooks/deliveries/:id/redeliver", s.redeliverWebhookHandler)
+}
 
-	s.router.GET("/", s.indexHandler)
-	s.router.GET("/api/packs", s.listPacksHandler)
-	s.router.GET("/api/packs/:name", s.getPackHandler)
-	s.router.GET("/download/:name", s.downloadPackHandler)
-	s.router.GET("/hash/:name", s.hashHandler)
-	s.router.GET("/api/rescan", s.rescanPacksHandler)
-	s.router.GET("/debug", s.debugHandler)
+func (s *Server) healthHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"status":  "ok",
+	})
 }
 
 func (s *Server) indexHandler(c *gin.Context) {
 	resourcePacks := s.packsManager.GetAllPacks()
+	var downloads map[string]int64
+	if s.stats != nil {
+		downloads = s.stats.Downloads(defaultStatsDays)
+	}
 
 	htmlContent := fmt.Sprintf(`
 <!DOCTYPE html>
@@ -65,7 +135,10 @@ func (s *Server) indexHandler(c *gin.Context) {
         .container { max-width: 800px; margin: 0 auto; background: white; padding: 30px; border-radius: 10px; box-shadow: 0 2px 10px rgba(0,0,0,0.1); }
         h1 { color: #2c3e50; text-align: center; margin-bottom: 30px; }
         .pack-card { border: 1px solid #ddd; padding: 20px; margin-bottom: 20px; border-radius: 8px; background: #fafafa; }
-        .pack-name { font-size: 1.3em; font-weight: bold; color: #2c3e50; margin-bottom: 10px; }
+        .pack-name { font-size: 1.3em; font-weight: bold; color: #2c3e50; margin-bottom: 10px; display: flex; align-items: center; gap: 10px; }
+        .pack-name a { color: inherit; text-decoration: none; }
+        .pack-name a:hover { text-decoration: underline; }
+        .pack-icon { width: 48px; height: 48px; image-rendering: pixelated; border-radius: 6px; }
         .pack-desc { color: #7f8c8d; margin-bottom: 15px; }
         .pack-meta { font-size: 0.9em; color: #95a5a6; margin-bottom: 15px; }
         .download-btn, .copy-btn { 
@@ -153,20 +226,31 @@ func (s *Server) indexHandler(c *gin.Context) {
 			sizeMB := float64(resourcePack.Size) / 1024 / 1024
 			htmlContent += fmt.Sprintf(`
         <div class="pack-card">
-            <div class="pack-name">%s</div>
+            <div class="pack-name">
+                <img class="pack-icon" src="/api/packs/%s/icon?size=64" alt="" onerror="this.style.display='none'">
+                <a href="/packs/%s">%s</a>
+            </div>
             <div class="pack-desc">%s</div>
             <div class="pack-meta">
-                格式: %d | 大小: %.2f MB<br>
+                格式: %d (%s) | 大小: %.2f MB<br>
                 类型: %s | 
-                更新时间: %s
+                更新时间: %s%s
             </div>
             <div class="hash-info">
-                <strong>Hash (MD5):</strong> %s
+                <strong>SHA-1:</strong> %s<br>
+                <strong>MD5:</strong> %s
             </div>
-            <a href="/download/%s" class="download-btn">下载资源包</a>
+            <a href="/download/%s/%s" class="download-btn">下载资源包</a>
             <button onclick="copyHash('%s')" class="copy-btn">复制 Hash</button>
         </div>
-`, resourcePack.Name, resourcePack.Description, resourcePack.PackFormat, sizeMB,
+`, url.PathEscape(resourcePack.Name), url.PathEscape(resourcePack.Name), html.EscapeString(resourcePack.Name),
+				resourcePack.DescriptionHTML(), resourcePack.PackFormat,
+				func() string {
+					if resourcePack.MinecraftVersions == nil {
+						return "未知版本"
+					}
+					return "Minecraft " + resourcePack.MinecraftVersions.String()
+				}(), sizeMB,
 				func() string {
 					if resourcePack.IsDirectory {
 						return "目录"
@@ -174,7 +258,13 @@ func (s *Server) indexHandler(c *gin.Context) {
 						return "ZIP文件"
 					}
 				}(),
-				resourcePack.LastModified.Format("2006-01-02 15:04:05"), resourcePack.Hash, resourcePack.Name, resourcePack.Hash)
+				resourcePack.LastModified.Format("2006-01-02 15:04:05"),
+				func() string {
+					if downloads == nil {
+						return ""
+					}
+					return fmt.Sprintf(" | 近 %d 天下载: %d 次", defaultStatsDays, downloads[resourcePack.Name])
+				}(), resourcePack.SHA1, resourcePack.Hash, url.PathEscape(resourcePack.Name), resourcePack.SHA1, resourcePack.SHA1)
 		}
 	}
 
@@ -194,6 +284,18 @@ func (s *Server) indexHandler(c *gin.Context) {
 
 func (s *Server) listPacksHandler(c *gin.Context) {
 	resourcePacks := s.packsManager.GetAllPacks()
+	if mcVersion := c.Query("mc_version"); mcVersion != "" {
+		var err error
+		resourcePacks, err = s.packsManager.GetPacksForVersion(mcVersion)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+
 	packsData := make([]map[string]interface{}, 0, len(resourcePacks))
 
 	for _, resourcePack := range resourcePacks {
@@ -207,25 +309,42 @@ func (s *Server) listPacksHandler(c *gin.Context) {
 	})
 }
 
-func (s *Server) getPackHandler(c *gin.Context) {
+func (s *Server) validatePackHandler(c *gin.Context) {
 	name := c.Param("name")
-	resourcePack := s.packsManager.GetPack(name)
-
-	if resourcePack == nil {
+	report, err := s.packsManager.ValidatePack(name)
+	if errors.Is(err, pack.ErrPackNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"success": false,
 			"error":   "资源包不存在",
 		})
 		return
 	}
+	if err != nil {
+		s.logger.Error("校验资源包失败", zap.String("name", name), zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"success": false,
+			"error":   "校验资源包失败",
+		})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data":    resourcePack.ToMap(),
+		"data":    report,
 	})
 }
 
-func (s *Server) downloadPackHandler(c *gin.Context) {
+func (s *Server) listFormatsHandler(c *gin.Context) {
+	entries := s.packsManager.GetFormatTable().Entries()
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    entries,
+		"count":   len(entries),
+	})
+}
+
+func (s *Server) getPackHandler(c *gin.Context) {
 	name := c.Param("name")
 	resourcePack := s.packsManager.GetPack(name)
 
@@ -237,31 +356,28 @@ func (s *Server) downloadPackHandler(c *gin.Context) {
 		return
 	}
 
-	if resourcePack.IsDirectory {
-		// 创建临时zip文件
-		zipPath, err := s.packsManager.CreateZipFromDirectory(resourcePack.Path, resourcePack.Name)
-		if err != nil {
-			s.logger.Error("创建zip文件失败", zap.Error(err))
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   "资源包文件生成失败",
-			})
-			return
-		}
+	data := resourcePack.ToMap()
+	data["description_html"] = resourcePack.DescriptionHTML()
+	data["mcmeta"] = resourcePack.Mcmeta
 
-		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.zip\"", resourcePack.Name))
-		c.Header("Content-Type", "application/zip")
-		c.File(zipPath)
-	} else {
-		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.zip\"", resourcePack.Name))
-		c.Header("Content-Type", "application/zip")
-		c.File(resourcePack.Path)
-	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
+	})
 }
 
 func (s *Server) hashHandler(c *gin.Context) {
 	name := c.Param("name")
-	hash := s.packsManager.GetPackHash(name)
+	algo := strings.ToLower(c.DefaultQuery("algo", pack.HashMD5))
+	hash, ok := s.packsManager.GetPackDigest(name, algo)
+
+	if !ok && s.packsManager.GetPack(name) != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "不支持的 Hash 算法，可选值: sha1, sha256, md5",
+		})
+		return
+	}
 
 	if hash == "" {
 		c.JSON(http.StatusNotFound, gin.H{
@@ -276,7 +392,7 @@ func (s *Server) hashHandler(c *gin.Context) {
 		"data": gin.H{
 			"name":      name,
 			"hash":      hash,
-			"hash_type": "MD5",
+			"hash_type": strings.ToUpper(algo),
 		},
 	})
 }
@@ -303,18 +419,35 @@ func (s *Server) debugHandler(c *gin.Context) {
 			"host":  s.config.Server.Host,
 			"port":  s.config.Server.Port,
 			"debug": s.config.Server.Debug,
+			"auth":  s.auth.enabled,
+			"signed_downloads": gin.H{
+				"enabled":  s.signer.enabled(),
+				"required": s.signer.required,
+			},
 		},
 		"packs": gin.H{
 			"directory": s.packsManager.GetPacksDirectory(),
 			"count":     len(s.packsManager.GetAllPacks()),
 		},
 		"endpoints": gin.H{
-			"list_packs": "/api/packs",
-			"get_pack":   "/api/packs/{name}",
-			"download":   "/download/{name}",
-			"hash":       "/hash/{name}",
-			"rescan":     "/api/rescan",
-			"debug":      "/debug",
+			"list_packs":       "/api/packs",
+			"get_pack":         "/api/packs/{name}",
+			"download":         "/download/{name}",
+			"download_version": "/download/{name}/{sha1}",
+			"blob":             "/blobs/{sha1}",
+			"hash":             "/hash/{name}?algo=sha1|sha256|md5",
+			"signed_url":       "/api/packs/{name}/signed-url",
+			"versions":         "/api/packs/{name}/versions",
+			"rollback":         "/api/packs/{name}/rollback",
+			"channels":         "/api/packs/{name}/channels",
+			"promote":          "/api/packs/{name}/promote",
+			"events":           "/api/events",
+			"webhooks":         "/api/webhooks/deliveries",
+			"metrics":          "/metrics",
+			"stats":            "/api/packs/{name}/stats?days=30",
+			"rescan":           "/api/rescan",
+			"debug":            "/debug",
+			"health":           "/healthz",
 		},
 		"timestamp": time.Now().Unix(),
 	}
@@ -0,0 +1,289 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"resourcepack-server/config"
+	"resourcepack-server/pack"
+)
+
+var (
+	errSignatureInvalid = errors.New("下载链接签名无效")
+	errSignatureExpired = errors.New("下载链接已过期")
+	errPlayerMismatch   = errors.New("下载链接不属于当前玩家")
+)
+
+// urlSigner 生成与校验带 HMAC 签名的下载链接。第一个密钥用于签名，
+// 其余密钥仅用于校验，轮换时将新密钥放在首位，旧密钥保留到其签发的链接全部过期。
+type urlSigner struct {
+	secrets    [][]byte
+	required   bool
+	clockSkew  time.Duration
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+func newURLSigner(cfg config.SigningConfig) *urlSigner {
+	signer := &urlSigner{
+		required:   cfg.Required,
+		clockSkew:  time.Duration(cfg.ClockSkew * float64(time.Second)),
+		defaultTTL: time.Duration(cfg.DefaultTTL * float64(time.Second)),
+		maxTTL:     time.Duration(cfg.MaxTTL * float64(time.Second)),
+	}
+	for _, key := range cfg.Keys {
+		if key.Secret != "" {
+			signer.secrets = append(signer.secrets, []byte(key.Secret))
+		}
+	}
+	return signer
+}
+
+func (us *urlSigner) enabled() bool {
+	return len(us.secrets) > 0
+}
+
+// normalizeUUID 统一玩家 UUID 的格式，客户端发送的 X-Minecraft-UUID 不带连字符
+func normalizeUUID(uuid string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(uuid), "-", ""))
+}
+
+// downloadTarget 返回签名绑定的下载目标：资源包当前版本、发布渠道或以 Hash 寻址的版本，
+// 签名只对签发时的目标有效，不能用于同一资源包的其他渠道或历史版本。
+func downloadTarget(name, channel, sha1 string) string {
+	target := "/download/" + name
+	if sha1 != "" {
+		target += "/" + strings.ToLower(sha1)
+	}
+	if channel != "" {
+		target += "?channel=" + channel
+	}
+	return target
+}
+
+// requestTarget 返回下载请求对应的签名目标，/blobs 不属于任何资源包，只能以 /blobs 目标签名
+func requestTarget(c *gin.Context) string {
+	if sha1 := c.Param("sha1"); sha1 != "" {
+		return "/blobs/" + strings.ToLower(sha1)
+	}
+	return downloadTarget(c.Param("name"), c.Query("channel"), c.Param("hash"))
+}
+
+func signaturePayload(target string, expires int64, player string) []byte {
+	return []byte(fmt.Sprintf("%s\n%d\n%s", target, expires, player))
+}
+
+func computeSignature(secret, payload []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+func (us *urlSigner) sign(target string, expires int64, player string) string {
+	sig := computeSignature(us.secrets[0], signaturePayload(target, expires, normalizeUUID(player)))
+	return base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// signedQuery 返回下载链接的查询参数
+func (us *urlSigner) signedQuery(target string, expires time.Time, player string) url.Values {
+	query := url.Values{}
+	query.Set("exp", strconv.FormatInt(expires.Unix(), 10))
+	if player != "" {
+		query.Set("uuid", normalizeUUID(player))
+	}
+	query.Set("sig", us.sign(target, expires.Unix(), player))
+	return query
+}
+
+// verify 校验请求中针对 target 的签名。过期时间允许 clockSkew 的误差；
+// 链接绑定了玩家时要求客户端发送的 X-Minecraft-UUID 与之相同。
+func (us *urlSigner) verify(r *http.Request, target string) error {
+	query := r.URL.Query()
+	sig, err := base64.RawURLEncoding.DecodeString(query.Get("sig"))
+	if err != nil || len(sig) != sha256.Size {
+		return errSignatureInvalid
+	}
+	expires, err := strconv.ParseInt(query.Get("exp"), 10, 64)
+	if err != nil {
+		return errSignatureInvalid
+	}
+
+	player := normalizeUUID(query.Get("uuid"))
+	payload := signaturePayload(target, expires, player)
+	valid := false
+	for _, secret := range us.secrets {
+		if hmac.Equal(sig, computeSignature(secret, payload)) {
+			valid = true
+		}
+	}
+	if !valid {
+		return errSignatureInvalid
+	}
+
+	if time.Now().After(time.Unix(expires, 0).Add(us.clockSkew)) {
+		return errSignatureExpired
+	}
+	if player != "" && normalizeUUID(r.Header.Get("X-Minecraft-UUID")) != player {
+		return errPlayerMismatch
+	}
+	return nil
+}
+
+func isSignedRequest(c *gin.Context) bool {
+	return c.Query("sig") != ""
+}
+
+// requireDownload 控制下载接口的访问。带签名的请求在查找资源包之前按请求的下载目标校验签名，
+// 避免未通过校验的请求根据响应状态码推断资源包与版本是否存在；
+// signing.required 开启时，未签名的请求必须携带拥有 download 角色的 API Key。
+func (s *Server) requireDownload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.signer.enabled() && isSignedRequest(c) {
+			if err := s.signer.verify(c.Request, requestTarget(c)); err != nil {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"success": false,
+					"error":   err.Error(),
+				})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if !s.signer.required {
+			if s.authorize(c, roleDownload, true) {
+				c.Next()
+			}
+			return
+		}
+
+		if requestToken(c) == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "需要使用签名的下载链接",
+			})
+			return
+		}
+		if s.authorize(c, roleDownload, false) {
+			c.Next()
+		}
+	}
+}
+
+type signURLRequest struct {
+	TTL     float64 `json:"ttl" form:"ttl"`
+	Player  string  `json:"player" form:"player"`
+	Channel string  `json:"channel" form:"channel"`
+	SHA1    string  `json:"sha1" form:"sha1"`
+}
+
+// signURLHandler 为资源包生成带签名的下载链接，ttl 以秒为单位。
+// 默认签发当前版本的链接，指定 channel 或 sha1 时签发对应发布渠道或历史版本的链接。
+func (s *Server) signURLHandler(c *gin.Context) {
+	if !s.signer.enabled() {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"success": false,
+			"error":   "未配置下载链接签名密钥",
+		})
+		return
+	}
+
+	var req signURLRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	ttl := s.signer.defaultTTL
+	if req.TTL > 0 {
+		ttl = time.Duration(req.TTL * float64(time.Second))
+	}
+	if s.signer.maxTTL > 0 && ttl > s.signer.maxTTL {
+		ttl = s.signer.maxTTL
+	}
+	if req.Player != "" && len(normalizeUUID(req.Player)) != 32 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "玩家 UUID 格式无效",
+		})
+		return
+	}
+
+	if req.Channel != "" && req.SHA1 != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success": false,
+			"error":   "channel 与 sha1 不能同时指定",
+		})
+		return
+	}
+
+	name := c.Param("name")
+	var resourcePack *pack.ResourcePack
+	var err error
+	switch {
+	case req.Channel != "":
+		resourcePack, err = s.packsManager.GetPackChannel(name, req.Channel)
+	case req.SHA1 != "":
+		resourcePack, err = s.packsManager.GetPackVersion(name, req.SHA1)
+	default:
+		if resourcePack = s.packsManager.GetPack(name); resourcePack == nil {
+			err = pack.ErrPackNotFound
+		}
+	}
+	if errors.Is(err, pack.ErrChannelNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "发布渠道不存在",
+		})
+		return
+	}
+	if err != nil {
+		s.versionLookupError(c, err)
+		return
+	}
+
+	expires := time.Now().Add(ttl)
+	query := s.signer.signedQuery(downloadTarget(name, req.Channel, req.SHA1), expires, req.Player)
+	path := "/download/" + url.PathEscape(name)
+	if req.SHA1 != "" {
+		path += "/" + strings.ToLower(req.SHA1)
+	}
+	if req.Channel != "" {
+		query.Set("channel", req.Channel)
+	}
+	path += "?" + query.Encode()
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"name":       name,
+			"sha1":       resourcePack.SHA1,
+			"path":       path,
+			"url":        requestBaseURL(c) + path,
+			"expires_at": expires.Unix(),
+		},
+	})
+}
+
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+	return scheme + "://" + c.Request.Host
+}
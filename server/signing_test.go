@@ -0,0 +1,131 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"resourcepack-server/config"
+)
+
+const testPlayer = "069a79f4-44e9-4726-a5be-fca90e38aaf5"
+
+func testSigner(secrets ...string) *urlSigner {
+	cfg := config.SigningConfig{ClockSkew: 30, DefaultTTL: 3600, MaxTTL: 86400}
+	for i, secret := range secrets {
+		cfg.Keys = append(cfg.Keys, config.SigningKeyConfig{ID: string(rune('a' + i)), Secret: secret})
+	}
+	return newURLSigner(cfg)
+}
+
+func TestURLSignerVerify(t *testing.T) {
+	now := time.Now()
+	oldSigner := testSigner("old-secret")
+	current := testSigner("new-secret", "old-secret")
+
+	tests := []struct {
+		name    string
+		signer  *urlSigner
+		pack    string
+		expires time.Time
+		player  string
+		header  string
+		request string
+		want    error
+	}{
+		{name: "valid", signer: current, pack: "pack", expires: now.Add(time.Minute)},
+		{name: "rotated key", signer: oldSigner, pack: "pack", expires: now.Add(time.Minute)},
+		{name: "unknown key", signer: testSigner("other-secret"), pack: "pack", expires: now.Add(time.Minute), want: errSignatureInvalid},
+		{name: "expired within skew", signer: current, pack: "pack", expires: now.Add(-10 * time.Second)},
+		{name: "expired beyond skew", signer: current, pack: "pack", expires: now.Add(-time.Minute), want: errSignatureExpired},
+		{name: "tampered name", signer: current, pack: "pack", request: "other", expires: now.Add(time.Minute), want: errSignatureInvalid},
+		{name: "player matches", signer: current, pack: "pack", expires: now.Add(time.Minute), player: testPlayer, header: "069A79F444E94726A5BEFCA90E38AAF5"},
+		{name: "player mismatch", signer: current, pack: "pack", expires: now.Add(time.Minute), player: testPlayer, header: "853c80ef3c3749fdaa49938b674adae6", want: errPlayerMismatch},
+		{name: "player missing", signer: current, pack: "pack", expires: now.Add(time.Minute), player: testPlayer, want: errPlayerMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.signer.signedQuery(tt.pack, tt.expires, tt.player)
+			req := httptest.NewRequest("GET", "/download/"+tt.pack+"?"+query.Encode(), nil)
+			if tt.header != "" {
+				req.Header.Set("X-Minecraft-UUID", tt.header)
+			}
+
+			name := tt.pack
+			if tt.request != "" {
+				name = tt.request
+			}
+			if err := current.verify(req, name); !errors.Is(err, tt.want) {
+				t.Fatalf("verify() = %v，期望 %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestURLSignerVerifyTamperedQuery(t *testing.T) {
+	signer := testSigner("secret")
+	expires := time.Now().Add(time.Minute)
+
+	tests := []struct {
+		name   string
+		mutate func(query url.Values)
+	}{
+		{"missing signature", func(q url.Values) { delete(q, "sig") }},
+		{"malformed signature", func(q url.Values) { q["sig"] = []string{"not-base64!"} }},
+		{"extended expiry", func(q url.Values) { q["exp"] = []string{"99999999999"} }},
+		{"malformed expiry", func(q url.Values) { q["exp"] = []string{"soon"} }},
+		{"player removed", func(q url.Values) { delete(q, "uuid") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := signer.signedQuery("pack", expires, testPlayer)
+			tt.mutate(query)
+			req := httptest.NewRequest("GET", "/download/pack?"+query.Encode(), nil)
+			req.Header.Set("X-Minecraft-UUID", testPlayer)
+
+			if err := signer.verify(req, "pack"); !errors.Is(err, errSignatureInvalid) {
+				t.Fatalf("verify() = %v，期望 %v", err, errSignatureInvalid)
+			}
+		})
+	}
+}
+
+func TestSignedLinkBoundToTarget(t *testing.T) {
+	const sha1 = "0123456789abcdef0123456789abcdef01234567"
+	s := newRouteTestServer(config.Config{Signing: config.SigningConfig{
+		ClockSkew: 30,
+		Keys:      []config.SigningKeyConfig{{ID: "a", Secret: "secret"}},
+	}})
+	expires := time.Now().Add(time.Minute)
+	stable := s.signer.signedQuery(downloadTarget("pack", "", ""), expires, "")
+	beta := s.signer.signedQuery(downloadTarget("pack", "beta", ""), expires, "")
+	beta.Set("channel", "beta")
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"stable link on channel", "/download/pack?channel=beta&" + stable.Encode()},
+		{"stable link on version", "/download/pack/" + sha1 + "?" + stable.Encode()},
+		{"stable link on blob", "/blobs/" + sha1 + "?" + stable.Encode()},
+		{"stable link on other pack", "/download/other?" + stable.Encode()},
+		{"channel link on other channel", "/download/pack?" + strings.Replace(beta.Encode(), "channel=beta", "channel=alpha", 1)},
+		{"channel link on stable", "/download/pack?" + strings.Replace(beta.Encode(), "channel=beta&", "", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.router.ServeHTTP(rec, httptest.NewRequest("GET", tt.path, nil))
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("GET %s = %d，期望 %d", tt.path, rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"resourcepack-server/pack"
+	"resourcepack-server/stats"
+)
+
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 366
+)
+
+// minecraftVersion 识别原版客户端下载资源包时发送的 X-Minecraft-Version 请求头，
+// 以及 "Minecraft Java/<版本>" 形式的 User-Agent
+func minecraftVersion(c *gin.Context) string {
+	if version := c.GetHeader("X-Minecraft-Version"); version != "" {
+		return version
+	}
+	if version, ok := strings.CutPrefix(c.Request.UserAgent(), "Minecraft Java/"); ok {
+		return strings.Fields(version + " ")[0]
+	}
+	return ""
+}
+
+// recordDownloadEvent 在资源包发送完成后写入下载统计，发送的字节数少于 Content-Length 视为中断
+func (s *Server) recordDownloadEvent(c *gin.Context, resourcePack *pack.ResourcePack) {
+	status := c.Writer.Status()
+	if s.stats == nil || c.Request.Method != http.MethodGet || (status != http.StatusOK && status != http.StatusPartialContent) {
+		return
+	}
+
+	written := int64(c.Writer.Size())
+	if written < 0 {
+		written = 0
+	}
+	expected, err := strconv.ParseInt(c.Writer.Header().Get("Content-Length"), 10, 64)
+	if err != nil {
+		expected = resourcePack.Size
+	}
+
+	s.stats.Record(stats.DownloadEvent{
+		Time:             time.Now(),
+		Pack:             resourcePack.Name,
+		SHA1:             resourcePack.SHA1,
+		Bytes:            written,
+		Completed:        written == expected && c.Request.Context().Err() == nil,
+		Partial:          status == http.StatusPartialContent,
+		ClientIP:         c.ClientIP(),
+		UserAgent:        c.Request.UserAgent(),
+		MinecraftVersion: minecraftVersion(c),
+	})
+}
+
+func (s *Server) packStatsHandler(c *gin.Context) {
+	if s.stats == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"success": false,
+			"error":   "未启用下载统计",
+		})
+		return
+	}
+
+	name := c.Param("name")
+	if s.packsManager.GetPack(name) == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   "资源包不存在",
+		})
+		return
+	}
+
+	days := defaultStatsDays
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxStatsDays {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"success": false,
+				"error":   "days 必须在 1 到 366 之间",
+			})
+			return
+		}
+		days = parsed
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    s.stats.PackStats(name, days),
+	})
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"resourcepack-server/webhook"
+)
+
+// webhookDeliveriesHandler 返回 Webhook 投递记录，可按 webhook 与 status 筛选
+func (s *Server) webhookDeliveriesHandler(c *gin.Context) {
+	deliveries := s.dispatcher.Deliveries(c.Query("webhook"), c.Query("status"))
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"webhooks":   s.dispatcher.Webhooks(),
+			"deliveries": deliveries,
+			"count":      len(deliveries),
+		},
+	})
+}
+
+func (s *Server) redeliverWebhookHandler(c *gin.Context) {
+	delivery, err := s.dispatcher.Redeliver(c.Param("id"))
+	if errors.Is(err, webhook.ErrDeliveryNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    delivery,
+	})
+}
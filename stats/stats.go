@@ -0,0 +1,379 @@
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	dateLayout    = "2006-01-02"
+	filePrefix    = "downloads-"
+	fileSuffix    = ".jsonl"
+	maxTopEntries = 10
+
+	// User-Agent 与 Minecraft 版本由客户端提供，截断过长的值，并限制每天每个资源包记录的不同值数量，
+	// 超出后计入 otherValue，避免轮换请求头使内存无限增长
+	maxValueLength     = 128
+	maxDistinctValues  = 200
+	maxDistinctClients = 10000
+	otherValue         = "other"
+)
+
+// DownloadEvent 是一次资源包下载请求。Partial 表示 Range 请求，只计入字节数
+type DownloadEvent struct {
+	Time             time.Time `json:"time"`
+	Pack             string    `json:"pack"`
+	SHA1             string    `json:"sha1"`
+	Bytes            int64     `json:"bytes"`
+	Completed        bool      `json:"completed"`
+	Partial          bool      `json:"partial,omitempty"`
+	ClientIP         string    `json:"client_ip"`
+	UserAgent        string    `json:"user_agent,omitempty"`
+	MinecraftVersion string    `json:"minecraft_version,omitempty"`
+}
+
+type dayBucket struct {
+	downloads         int64
+	completed         int64
+	aborted           int64
+	bytes             int64
+	versions          map[string]int64
+	minecraftVersions map[string]int64
+	userAgents        map[string]int64
+	clients           map[string]bool
+	// extraClients 是超出 maxDistinctClients 后的下载次数，作为不同客户端数量的上限估计
+	extraClients int64
+}
+
+func newDayBucket() *dayBucket {
+	return &dayBucket{
+		versions:          make(map[string]int64),
+		minecraftVersions: make(map[string]int64),
+		userAgents:        make(map[string]int64),
+		clients:           make(map[string]bool),
+	}
+}
+
+func (b *dayBucket) add(event DownloadEvent) {
+	b.bytes += event.Bytes
+	if event.Partial {
+		return
+	}
+
+	b.downloads++
+	if event.Completed {
+		b.completed++
+	} else {
+		b.aborted++
+	}
+	b.versions[event.SHA1]++
+	if event.MinecraftVersion != "" {
+		addBounded(b.minecraftVersions, truncateValue(event.MinecraftVersion))
+	}
+	if event.UserAgent != "" {
+		addBounded(b.userAgents, truncateValue(event.UserAgent))
+	}
+	if b.clients[event.ClientIP] || len(b.clients) < maxDistinctClients {
+		b.clients[event.ClientIP] = true
+	} else {
+		b.extraClients++
+	}
+}
+
+func addBounded(counts map[string]int64, value string) {
+	if _, ok := counts[value]; !ok && len(counts) >= maxDistinctValues {
+		value = otherValue
+	}
+	counts[value]++
+}
+
+// truncateValue 截断到 maxValueLength 字节，不拆开多字节字符
+func truncateValue(value string) string {
+	if len(value) <= maxValueLength {
+		return value
+	}
+	cut := maxValueLength
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut]
+}
+
+// Store 将下载记录按天追加到 <state_dir>/stats/downloads-YYYY-MM-DD.jsonl，
+// 同时在内存中按资源包与日期汇总，启动时从保留期内的文件重建。
+type Store struct {
+	dir       string
+	retention int
+	logger    *zap.Logger
+
+	mu       sync.Mutex
+	packs    map[string]map[string]*dayBucket
+	file     *os.File
+	fileDate string
+	stop     chan struct{}
+}
+
+func NewStore(dir string, retentionDays int, logger *zap.Logger) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建统计目录失败: %w", err)
+	}
+
+	s := &Store{
+		dir:       dir,
+		retention: retentionDays,
+		logger:    logger,
+		packs:     make(map[string]map[string]*dayBucket),
+		stop:      make(chan struct{}),
+	}
+	s.applyRetention()
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	go s.retentionLoop()
+	return s, nil
+}
+
+func (s *Store) load() error {
+	files, err := filepath.Glob(filepath.Join(s.dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("读取下载统计失败: %w", err)
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var event DownloadEvent
+			// 进程异常退出时最后一行可能不完整，跳过即可
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				continue
+			}
+			s.addLocked(event)
+		}
+		file.Close()
+	}
+	return nil
+}
+
+func (s *Store) addLocked(event DownloadEvent) {
+	days, ok := s.packs[event.Pack]
+	if !ok {
+		days = make(map[string]*dayBucket)
+		s.packs[event.Pack] = days
+	}
+	date := event.Time.Format(dateLayout)
+	bucket, ok := days[date]
+	if !ok {
+		bucket = newDayBucket()
+		days[date] = bucket
+	}
+	bucket.add(event)
+}
+
+func (s *Store) Record(event DownloadEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event.UserAgent = truncateValue(event.UserAgent)
+	event.MinecraftVersion = truncateValue(event.MinecraftVersion)
+	s.addLocked(event)
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	date := event.Time.Format(dateLayout)
+	if s.file == nil || s.fileDate != date {
+		if s.file != nil {
+			s.file.Close()
+		}
+		s.file, err = os.OpenFile(filepath.Join(s.dir, filePrefix+date+fileSuffix), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			s.file = nil
+			s.logger.Error("打开下载统计文件失败", zap.Error(err))
+			return
+		}
+		s.fileDate = date
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		s.logger.Error("写入下载统计失败", zap.Error(err))
+	}
+}
+
+func (s *Store) retentionLoop() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.applyRetention()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// applyRetention 删除超过保留天数的文件与内存汇总，retention 为 0 时永久保留
+func (s *Store) applyRetention() {
+	if s.retention <= 0 {
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -s.retention).Format(dateLayout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, days := range s.packs {
+		for date := range days {
+			if date < cutoff {
+				delete(days, date)
+			}
+		}
+		if len(days) == 0 {
+			delete(s.packs, name)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(s.dir, filePrefix+"*"+fileSuffix))
+	for _, path := range files {
+		date := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), filePrefix), fileSuffix)
+		if date < cutoff {
+			if err := os.Remove(path); err != nil {
+				s.logger.Warn("删除过期下载统计失败", zap.String("path", path), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *Store) Close() {
+	close(s.stop)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+}
+
+type DailyCount struct {
+	Date      string `json:"date"`
+	Downloads int64  `json:"downloads"`
+	Bytes     int64  `json:"bytes"`
+}
+
+type RankedCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type PackStats struct {
+	Pack              string        `json:"pack"`
+	Days              int           `json:"days"`
+	Downloads         int64         `json:"downloads"`
+	Completed         int64         `json:"completed"`
+	Aborted           int64         `json:"aborted"`
+	Bytes             int64         `json:"bytes"`
+	UniqueClients     int           `json:"unique_clients"`
+	Daily             []DailyCount  `json:"daily"`
+	Versions          []RankedCount `json:"versions"`
+	MinecraftVersions []RankedCount `json:"minecraft_versions"`
+	UserAgents        []RankedCount `json:"user_agents"`
+}
+
+// PackStats 汇总资源包最近 days 天（含今天）的下载统计
+func (s *Store) PackStats(name string, days int) *PackStats {
+	result := &PackStats{Pack: name, Days: days, Daily: []DailyCount{}}
+	since := time.Now().AddDate(0, 0, -(days - 1)).Format(dateLayout)
+
+	versions := make(map[string]int64)
+	minecraftVersions := make(map[string]int64)
+	userAgents := make(map[string]int64)
+	clients := make(map[string]bool)
+	var extraClients int64
+
+	s.mu.Lock()
+	for date, bucket := range s.packs[name] {
+		if date < since {
+			continue
+		}
+		result.Downloads += bucket.downloads
+		result.Completed += bucket.completed
+		result.Aborted += bucket.aborted
+		result.Bytes += bucket.bytes
+		result.Daily = append(result.Daily, DailyCount{Date: date, Downloads: bucket.downloads, Bytes: bucket.bytes})
+		mergeCounts(versions, bucket.versions)
+		mergeCounts(minecraftVersions, bucket.minecraftVersions)
+		mergeCounts(userAgents, bucket.userAgents)
+		for client := range bucket.clients {
+			clients[client] = true
+		}
+		extraClients += bucket.extraClients
+	}
+	s.mu.Unlock()
+
+	sort.Slice(result.Daily, func(i, j int) bool {
+		return result.Daily[i].Date < result.Daily[j].Date
+	})
+	result.UniqueClients = len(clients) + int(extraClients)
+	result.Versions = topCounts(versions)
+	result.MinecraftVersions = topCounts(minecraftVersions)
+	result.UserAgents = topCounts(userAgents)
+	return result
+}
+
+// Downloads 返回每个资源包最近 days 天的下载次数，用于首页展示
+func (s *Store) Downloads(days int) map[string]int64 {
+	since := time.Now().AddDate(0, 0, -(days - 1)).Format(dateLayout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int64, len(s.packs))
+	for name, buckets := range s.packs {
+		for date, bucket := range buckets {
+			if date >= since {
+				counts[name] += bucket.downloads
+			}
+		}
+	}
+	return counts
+}
+
+func mergeCounts(dst, src map[string]int64) {
+	for key, count := range src {
+		dst[key] += count
+	}
+}
+
+func topCounts(counts map[string]int64) []RankedCount {
+	ranked := make([]RankedCount, 0, len(counts))
+	for value, count := range counts {
+		ranked = append(ranked, RankedCount{Value: value, Count: count})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Count != ranked[j].Count {
+			return ranked[i].Count > ranked[j].Count
+		}
+		return ranked[i].Value < ranked[j].Value
+	})
+	if len(ranked) > maxTopEntries {
+		ranked = ranked[:maxTopEntries]
+	}
+	return ranked
+}
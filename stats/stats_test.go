@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+func TestDayBucketBoundsClientValues(t *testing.T) {
+	bucket := newDayBucket()
+	for i := 0; i < maxDistinctValues+50; i++ {
+		bucket.add(DownloadEvent{
+			SHA1:             "sha1",
+			ClientIP:         fmt.Sprintf("10.0.%d.%d", i/256, i%256),
+			UserAgent:        fmt.Sprintf("agent-%d-%s", i, strings.Repeat("x", 4096)),
+			MinecraftVersion: fmt.Sprintf("1.%d", i),
+		})
+	}
+
+	if len(bucket.userAgents) != maxDistinctValues+1 {
+		t.Fatalf("userAgents 中有 %d 个值，期望 %d", len(bucket.userAgents), maxDistinctValues+1)
+	}
+	if bucket.userAgents[otherValue] != 50 || bucket.minecraftVersions[otherValue] != 50 {
+		t.Fatalf("超出上限的值未计入 %q: %d, %d", otherValue, bucket.userAgents[otherValue], bucket.minecraftVersions[otherValue])
+	}
+	for value := range bucket.userAgents {
+		if len(value) > maxValueLength {
+			t.Fatalf("值未被截断: %d 字节", len(value))
+		}
+	}
+}
+
+func TestDayBucketBoundsClients(t *testing.T) {
+	bucket := newDayBucket()
+	for i := 0; i < maxDistinctClients+10; i++ {
+		bucket.add(DownloadEvent{SHA1: "sha1", ClientIP: fmt.Sprintf("client-%d", i)})
+	}
+	bucket.add(DownloadEvent{SHA1: "sha1", ClientIP: "client-0"})
+
+	if len(bucket.clients) != maxDistinctClients || bucket.extraClients != 10 {
+		t.Fatalf("clients = %d, extraClients = %d", len(bucket.clients), bucket.extraClients)
+	}
+}
+
+func TestTruncateValueKeepsRunes(t *testing.T) {
+	value := strings.Repeat("a", maxValueLength-1) + "我的世界"
+	truncated := truncateValue(value)
+	if len(truncated) > maxValueLength || !utf8.ValidString(truncated) {
+		t.Fatalf("truncateValue() = %q", truncated)
+	}
+	if truncateValue("Minecraft/1.21.4") != "Minecraft/1.21.4" {
+		t.Fatal("短值不应被修改")
+	}
+}
+
+func TestStoreRecordTruncatesPersistedValues(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewStore(dir, 0, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Record(DownloadEvent{Time: time.Now(), Pack: "pack", SHA1: "sha1", UserAgent: strings.Repeat("x", 10000)})
+	store.Close()
+
+	reloaded, err := NewStore(dir, 0, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.Close()
+	stats := reloaded.PackStats("pack", 1)
+	if len(stats.UserAgents) != 1 || len(stats.UserAgents[0].Value) != maxValueLength {
+		t.Fatalf("UserAgents = %+v", stats.UserAgents)
+	}
+}
@@ -0,0 +1,521 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"resourcepack-server/config"
+	"resourcepack-server/pack"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+const (
+	defaultMaxAttempts = 8
+	defaultTimeout     = 10 * time.Second
+	initialBackoff     = 5 * time.Second
+	maxBackoff         = time.Hour
+	maxLogEntries      = 500
+)
+
+var ErrDeliveryNotFound = errors.New("投递记录不存在")
+
+var defaultEvents = []string{pack.EventPackAdded, pack.EventPackUpdated, pack.EventPackRemoved}
+
+type webhook struct {
+	name        string
+	url         string
+	secret      []byte
+	events      map[string]bool
+	maxAttempts int
+	timeout     time.Duration
+}
+
+// Delivery 是一次 Webhook 投递，失败后按指数退避重试，直到成功或达到最大次数
+type Delivery struct {
+	ID           string          `json:"id"`
+	Webhook      string          `json:"webhook"`
+	Event        string          `json:"event"`
+	EventID      uint64          `json:"event_id"`
+	Payload      json.RawMessage `json:"payload"`
+	Status       string          `json:"status"`
+	Attempts     int             `json:"attempts"`
+	CreatedAt    time.Time       `json:"created_at"`
+	NextAttempt  time.Time       `json:"next_attempt,omitempty"`
+	LastAttempt  time.Time       `json:"last_attempt,omitempty"`
+	ResponseCode int             `json:"response_code,omitempty"`
+	LastError    string          `json:"last_error,omitempty"`
+}
+
+// Dispatcher 订阅资源包事件并投递到配置的 Webhook。待投递队列与最近的投递记录
+// 保存在 <state_dir>/webhooks.json 中，重启后继续投递。
+type Dispatcher struct {
+	webhooks     map[string]*webhook
+	packsManager *pack.PacksManager
+	logger       *zap.Logger
+	client       *http.Client
+	statePath    string
+
+	mu         sync.Mutex
+	deliveries []*Delivery
+	wake       chan struct{}
+	stop       chan struct{}
+	done       sync.WaitGroup
+}
+
+func NewDispatcher(configs []config.WebhookConfig, stateDir string, packsManager *pack.PacksManager, logger *zap.Logger) (*Dispatcher, error) {
+	d := &Dispatcher{
+		webhooks:     make(map[string]*webhook),
+		packsManager: packsManager,
+		logger:       logger,
+		client:       &http.Client{},
+		statePath:    filepath.Join(stateDir, "webhooks.json"),
+		wake:         make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+	}
+
+	for i, cfg := range configs {
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("第 %d 个 Webhook 缺少 url", i+1)
+		}
+		name := cfg.Name
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		if _, ok := d.webhooks[name]; ok {
+			return nil, fmt.Errorf("Webhook 名称重复: %s", name)
+		}
+
+		hook := &webhook{
+			name:        name,
+			url:         cfg.URL,
+			secret:      []byte(cfg.Secret),
+			events:      make(map[string]bool),
+			maxAttempts: cfg.MaxAttempts,
+			timeout:     time.Duration(cfg.Timeout * float64(time.Second)),
+		}
+		if hook.maxAttempts <= 0 {
+			hook.maxAttempts = defaultMaxAttempts
+		}
+		if hook.timeout <= 0 {
+			hook.timeout = defaultTimeout
+		}
+		events := cfg.Events
+		if len(events) == 0 {
+			events = defaultEvents
+		}
+		for _, event := range events {
+			hook.events[event] = true
+		}
+		d.webhooks[name] = hook
+	}
+
+	if len(d.webhooks) == 0 {
+		return d, nil
+	}
+
+	if err := os.MkdirAll(stateDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建状态目录失败: %w", err)
+	}
+	data, err := os.ReadFile(d.statePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取 Webhook 队列失败: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &d.deliveries); err != nil {
+			return nil, fmt.Errorf("解析 Webhook 队列失败: %w", err)
+		}
+	}
+	return d, nil
+}
+
+// Start 开始订阅资源包事件并投递。启动扫描与上次运行时的资源包索引相比发现的变化（停机期间的新增、
+// 更新、移除）同样会被投递；没有索引时（首次运行或 --rebuild-index）启动扫描的事件不会被投递。
+func (d *Dispatcher) Start() {
+	if len(d.webhooks) == 0 {
+		return
+	}
+
+	d.done.Add(2)
+	go d.consumeEvents()
+	go d.deliverLoop()
+	d.logger.Info("Webhook 已启动", zap.Int("count", len(d.webhooks)))
+}
+
+func (d *Dispatcher) Stop() {
+	if len(d.webhooks) == 0 {
+		return
+	}
+	close(d.stop)
+	d.done.Wait()
+}
+
+func (d *Dispatcher) consumeEvents() {
+	defer d.done.Done()
+
+	lastID := d.packsManager.StartupEventID()
+	for {
+		replay, events, cancel := d.packsManager.Subscribe(lastID)
+		for _, event := range replay {
+			lastID = event.ID
+			d.enqueue(event)
+		}
+
+	receive:
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					// 处理过慢被断开，使用最后的事件 ID 重新订阅以补发
+					break receive
+				}
+				lastID = event.ID
+				d.enqueue(event)
+			case <-d.stop:
+				cancel()
+				return
+			}
+		}
+		cancel()
+	}
+}
+
+func (d *Dispatcher) enqueue(event pack.Event) {
+	if event.Type == pack.EventStreamReset {
+		d.logger.Warn("Webhook 事件订阅中断，部分事件未能投递")
+		return
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"event":    event.Type,
+		"event_id": event.ID,
+		"time":     event.Time.Unix(),
+		"data":     event.Data,
+	})
+	if err != nil {
+		d.logger.Error("生成 Webhook 内容失败", zap.Error(err))
+		return
+	}
+
+	d.mu.Lock()
+	queued := false
+	for _, hook := range d.webhooks {
+		if !hook.events[event.Type] {
+			continue
+		}
+		d.deliveries = append(d.deliveries, &Delivery{
+			ID:          newDeliveryID(),
+			Webhook:     hook.name,
+			Event:       event.Type,
+			EventID:     event.ID,
+			Payload:     payload,
+			Status:      StatusPending,
+			CreatedAt:   time.Now(),
+			NextAttempt: time.Now(),
+		})
+		queued = true
+	}
+	if queued {
+		d.saveLocked()
+	}
+	d.mu.Unlock()
+
+	if queued {
+		d.notify()
+	}
+}
+
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliverLoop 依次投递到期的记录，没有到期记录时等待到最早的重试时间或新的事件
+func (d *Dispatcher) deliverLoop() {
+	defer d.done.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		for _, delivery := range d.dueDeliveries() {
+			select {
+			case <-d.stop:
+				return
+			default:
+			}
+			d.attempt(delivery)
+		}
+
+		wait := d.nextWait()
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-timer.C:
+		case <-d.wake:
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) dueDeliveries() []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	var due []Delivery
+	for _, delivery := range d.deliveries {
+		if delivery.Status == StatusPending && !delivery.NextAttempt.After(now) {
+			due = append(due, *delivery)
+		}
+	}
+	return due
+}
+
+func (d *Dispatcher) nextWait() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	wait := maxBackoff
+	for _, delivery := range d.deliveries {
+		if delivery.Status != StatusPending {
+			continue
+		}
+		if until := time.Until(delivery.NextAttempt); until < wait {
+			wait = until
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+func (d *Dispatcher) attempt(delivery Delivery) {
+	hook, ok := d.webhooks[delivery.Webhook]
+	if !ok {
+		d.finish(delivery.ID, StatusFailed, 0, "Webhook 已从配置中移除")
+		return
+	}
+
+	code, err := d.send(hook, delivery)
+	if err == nil {
+		d.finish(delivery.ID, StatusSucceeded, code, "")
+		d.logger.Info("Webhook 投递成功", zap.String("webhook", hook.name), zap.String("event", delivery.Event))
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	record := d.findLocked(delivery.ID)
+	if record == nil {
+		return
+	}
+	record.Attempts++
+	record.LastAttempt = time.Now()
+	record.ResponseCode = code
+	record.LastError = err.Error()
+	if record.Attempts >= hook.maxAttempts {
+		record.Status = StatusFailed
+		d.logger.Error("Webhook 投递失败，已达到最大重试次数", zap.String("webhook", hook.name), zap.String("event", delivery.Event), zap.Error(err))
+	} else {
+		record.NextAttempt = time.Now().Add(backoff(record.Attempts))
+		d.logger.Warn("Webhook 投递失败，稍后重试", zap.String("webhook", hook.name), zap.String("event", delivery.Event), zap.Time("next_attempt", record.NextAttempt), zap.Error(err))
+	}
+	d.trimLocked()
+	d.saveLocked()
+}
+
+func (d *Dispatcher) finish(id, status string, code int, message string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	record := d.findLocked(id)
+	if record == nil {
+		return
+	}
+	record.Attempts++
+	record.LastAttempt = time.Now()
+	record.Status = status
+	record.ResponseCode = code
+	record.LastError = message
+	d.trimLocked()
+	d.saveLocked()
+}
+
+// backoff 返回第 attempts 次失败后的等待时间：5s、10s、20s……最长 1 小时
+func backoff(attempts int) time.Duration {
+	wait := initialBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxBackoff {
+		wait = maxBackoff
+	}
+	return wait
+}
+
+// send 发送一次请求。签名为 HMAC-SHA256(secret, "<timestamp>.<body>")，
+// 接收方应校验时间戳以防止重放。
+func (d *Dispatcher) send(hook *webhook, delivery Delivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, hook.url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "resourcepack-server-webhook")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", delivery.ID)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	if len(hook.secret) > 0 {
+		mac := hmac.New(sha256.New, hook.secret)
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(delivery.Payload)
+		req.Header.Set("X-Webhook-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := *d.client
+	client.Timeout = hook.timeout
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("响应状态码 %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) findLocked(id string) *Delivery {
+	for _, delivery := range d.deliveries {
+		if delivery.ID == id {
+			return delivery
+		}
+	}
+	return nil
+}
+
+// trimLocked 只保留最近的已完成记录，待投递的记录不受影响
+func (d *Dispatcher) trimLocked() {
+	finished := 0
+	for _, delivery := range d.deliveries {
+		if delivery.Status != StatusPending {
+			finished++
+		}
+	}
+	if finished <= maxLogEntries {
+		return
+	}
+
+	kept := d.deliveries[:0]
+	for _, delivery := range d.deliveries {
+		if delivery.Status != StatusPending && finished > maxLogEntries {
+			finished--
+			continue
+		}
+		kept = append(kept, delivery)
+	}
+	d.deliveries = kept
+}
+
+func (d *Dispatcher) saveLocked() {
+	data, err := json.MarshalIndent(d.deliveries, "", "  ")
+	if err != nil {
+		d.logger.Error("保存 Webhook 队列失败", zap.Error(err))
+		return
+	}
+
+	if err := pack.WriteFileAtomic(d.statePath, data, 0644); err != nil {
+		d.logger.Error("保存 Webhook 队列失败", zap.Error(err))
+	}
+}
+
+// Deliveries 按时间从新到旧返回投递记录，webhook 与 status 为空时不筛选
+func (d *Dispatcher) Deliveries(webhookName, status string) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deliveries := make([]Delivery, 0, len(d.deliveries))
+	for _, delivery := range d.deliveries {
+		if webhookName != "" && delivery.Webhook != webhookName {
+			continue
+		}
+		if status != "" && delivery.Status != status {
+			continue
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	return deliveries
+}
+
+// Redeliver 将投递记录重新加入队列，重试次数从零开始计算
+func (d *Dispatcher) Redeliver(id string) (Delivery, error) {
+	d.mu.Lock()
+	record := d.findLocked(id)
+	if record == nil {
+		d.mu.Unlock()
+		return Delivery{}, ErrDeliveryNotFound
+	}
+	record.Status = StatusPending
+	record.Attempts = 0
+	record.NextAttempt = time.Now()
+	d.saveLocked()
+	result := *record
+	d.mu.Unlock()
+
+	d.notify()
+	return result, nil
+}
+
+func (d *Dispatcher) Webhooks() []string {
+	names := make([]string, 0, len(d.webhooks))
+	for name := range d.webhooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newDeliveryID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}